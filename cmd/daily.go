@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/rybkr/sudoku/internal/generator"
+	"github.com/rybkr/sudoku/internal/output"
+	"github.com/rybkr/sudoku/internal/solver"
+	"github.com/rybkr/sudoku/internal/theme"
+)
+
+var (
+	dailyDate      string
+	dailyMonth     string
+	dailyTier      string
+	dailyType      string
+	dailyFormat    string
+	dailyOutput    string
+	dailySolutions string
+	dailyTheme     string
+	dailyTemplate  string
+	dailyPageSize  string
+	dailyPerPage   int
+)
+
+// monthFormat is the layout of the --month flag.
+const monthFormat = "2006-01"
+
+func init() {
+	dailyCmd := &cobra.Command{
+		Use:   "daily",
+		Short: "Print the puzzle of the day",
+		Long: `Print the puzzle of the day for a date, difficulty tier and board type.
+
+The puzzle, its layout and its solution are derived from the date, tier and
+type alone, so every machine running the same version of sudoku produces the
+same puzzle for the same day without sharing anything. The date defaults to
+today in UTC.
+
+With --month every day of a month is generated. The puzzles are written in
+any format 'sudoku gen' supports, in date order and labelled by date; JSON
+and CSV records carry the date, and an HTML booklet of a month opens with a
+calendar linking to each day's page. --solutions, --theme, --template,
+--page-size and --per-page work as they do for 'sudoku gen'.
+
+Examples:
+  sudoku daily
+  sudoku daily --date 2026-10-16 --tier hard
+  sudoku daily --tier expert --type jigsaw --format jsonl
+  sudoku daily --month 2026-10 --tier medium -o october.html
+  sudoku daily --month 2026-10 --solutions section -o october.pdf
+  sudoku daily --month 2026-10 --format json -o october.json`,
+		Args: cobra.NoArgs,
+		RunE: runDaily,
+	}
+
+	dailyCmd.Flags().StringVar(&dailyDate, "date", "", "Day of the puzzle as YYYY-MM-DD (default today, UTC)")
+	dailyCmd.Flags().StringVar(&dailyMonth, "month", "", "Generate every day of a month given as YYYY-MM")
+	dailyCmd.Flags().StringVarP(&dailyTier, "tier", "d", "medium", "Difficulty tier: easy, medium, hard, expert or diabolical")
+	dailyCmd.Flags().StringVar(&dailyType, "type", "standard", "Board type: standard or jigsaw")
+	dailyCmd.Flags().StringVar(&dailyFormat, "format", "", "Output format: "+strings.Join(output.Names(), ", ")+" (default from the --output extension, else text)")
+	dailyCmd.Flags().StringVarP(&dailyOutput, "output", "o", "", "Output file; its extension picks the format (default stdout)")
+	dailyCmd.Flags().StringVar(&dailySolutions, "solutions", "", "Where solutions go: none, inline, section or file (default depends on the format)")
+	dailyCmd.Flags().StringVarP(&dailyTheme, "theme", "t", "", "Theme for HTML output; see 'sudoku themes list'")
+	dailyCmd.Flags().StringVar(&themeDir, "theme-dir", "", "Extra directory of HTML themes")
+	dailyCmd.Flags().StringVar(&dailyTemplate, "template", "", "HTML template file to use instead of the built-in one")
+	dailyCmd.Flags().StringVar(&dailyPageSize, "page-size", "a4", "Page size for PDF and HTML output: a4, a5 or letter")
+	dailyCmd.Flags().IntVar(&dailyPerPage, "per-page", 1, "Puzzles per page for PDF and HTML output: 1, 2, 4 or 6")
+
+	rootCmd.AddCommand(dailyCmd)
+}
+
+func runDaily(cmd *cobra.Command, args []string) error {
+	switch dailyType {
+	case "jigsaw", "standard", "":
+	default:
+		return fmt.Errorf("unknown board type %q: must be standard or jigsaw", dailyType)
+	}
+	tier, err := solver.ParseCategory(dailyTier)
+	if err != nil {
+		return err
+	}
+
+	format, filename, err := resolveOutput(dailyFormat, dailyOutput)
+	if err != nil {
+		return err
+	}
+	placement, err := output.ParseSolutions(dailySolutions)
+	if err != nil {
+		return err
+	}
+	paper, err := output.ParsePageSize(dailyPageSize)
+	if err != nil {
+		return err
+	}
+	if !output.ValidPerPage(dailyPerPage) {
+		return fmt.Errorf("puzzles per page (%d) must be 1, 2, 4 or 6", dailyPerPage)
+	}
+	if dailyTemplate != "" && format.Name != "html" {
+		return fmt.Errorf("--template needs html output, not %s", format.Name)
+	}
+
+	var dates []time.Time
+	switch {
+	case dailyMonth != "" && dailyDate != "":
+		return fmt.Errorf("give either --date or --month, not both")
+	case dailyMonth != "":
+		first, err := time.Parse(monthFormat, dailyMonth)
+		if err != nil {
+			return fmt.Errorf("invalid month %q: use YYYY-MM", dailyMonth)
+		}
+		for d := first; d.Month() == first.Month(); d = d.AddDate(0, 0, 1) {
+			dates = append(dates, d)
+		}
+	case dailyDate != "":
+		d, err := time.Parse(generator.DateFormat, dailyDate)
+		if err != nil {
+			return fmt.Errorf("invalid date %q: use YYYY-MM-DD", dailyDate)
+		}
+		dates = []time.Time{d}
+	default:
+		dates = []time.Time{time.Now().UTC()}
+	}
+
+	// Flags are valid; failures from here on are not usage errors.
+	cmd.SilenceUsage = true
+
+	var tmpl *output.Template
+	if dailyTemplate != "" {
+		if tmpl, err = output.ParseTemplate(dailyTemplate); err != nil {
+			return err
+		}
+	}
+	var bookTheme *theme.Theme
+	if format.Themed {
+		dirs, err := themeDirs()
+		if err != nil {
+			return err
+		}
+		if bookTheme, err = theme.Find(dailyTheme, dirs...); err != nil {
+			return err
+		}
+	}
+
+	puzzles, err := generateDailies(cmd.Context(), dates, tier, dailyType)
+	if err != nil {
+		return err
+	}
+
+	// There is no run seed: the date, tier and type regenerate each puzzle,
+	// so records carry the date instead.
+	w, err := output.Open(format, filename, output.Options{
+		Solutions: placement,
+		Theme:     bookTheme,
+		Template:  tmpl,
+		PageSize:  paper,
+		PerPage:   dailyPerPage,
+	})
+	if err != nil {
+		return err
+	}
+	defer w.Abort()
+
+	for i, d := range puzzles {
+		err := w.Write(output.Puzzle{
+			Number:   i + 1,
+			Seed:     d.PuzzleSeed,
+			Date:     d.Date,
+			Puzzle:   d.Puzzle,
+			Solution: d.Solution,
+			Rating:   d.Rating,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write puzzle for %s: %w", d.Date, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to write %s output: %w", format.Name, err)
+	}
+	if filename != "" {
+		fmt.Printf("Wrote %d daily puzzle(s) to %s\n", len(puzzles), outputFiles(format, filename, len(puzzles)))
+		if format.Placement(placement) == output.SolutionsFile {
+			fmt.Printf("Solutions in %s\n", outputFiles(format, output.SolutionsPath(filename), len(puzzles)))
+		}
+	}
+	return nil
+}
+
+// generateDailies generates the puzzle of each date concurrently, returning
+// them in date order. The first failure cancels the rest and is the error
+// returned.
+func generateDailies(ctx context.Context, dates []time.Time, tier solver.Category, boardType string) ([]*generator.Daily, error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	puzzles := make([]*generator.Daily, len(dates))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(runtime.NumCPU(), len(dates)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				d, err := generator.GenerateDaily(ctx, dates[i], tier, boardType)
+				if err != nil {
+					// Only the first cause is kept, so the other dates'
+					// cancellations cannot hide it.
+					cancel(fmt.Errorf("puzzle for %s: %w", dates[i].Format(generator.DateFormat), err))
+					continue
+				}
+				puzzles[i] = d
+			}
+		}()
+	}
+	for i := range dates {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := context.Cause(ctx); err != nil {
+		return nil, err
+	}
+	return puzzles, nil
+}
@@ -1,13 +1,12 @@
 package cmd
 
 import (
-	"embed"
+	"context"
+	"errors"
 	"fmt"
-	"html/template"
 	"math/rand"
 	"os"
-	"path/filepath"
-	"sort"
+	"runtime"
 	"strconv"
 	"strings"
 	"time"
@@ -16,51 +15,46 @@ import (
 
 	"github.com/rybkr/sudoku/internal/board"
 	"github.com/rybkr/sudoku/internal/generator"
+	"github.com/rybkr/sudoku/internal/output"
+	"github.com/rybkr/sudoku/internal/render"
 	"github.com/rybkr/sudoku/internal/solver"
+	"github.com/rybkr/sudoku/internal/theme"
 )
 
-//go:embed templates/*.html
-var templateFS embed.FS
-
-// PuzzlePage holds pre-rendered data for a single puzzle page in the HTML template.
-type PuzzlePage struct {
-    Title        string
-	PuzzleNumber int
-	Difficulty   int
-	GridHTML     template.HTML
-}
-
-// TemplateData holds data for HTML template rendering.
-type TemplateData struct {
-	TitlePrefix string
-	BodyFont    string
-	HeadingFont string
-	ThemeClass  string
-	PuzzlePages []PuzzlePage
-}
-
 var (
 	numPuzzles int
 	clueCount  string
 	outputFile string
-	theme      string
+	themeName  string
+	tmplFile   string
 	boardType  string
 	timeout    time.Duration
+	difficulty string
+	maxRetries int
+	workers    int
+	seed       int64
+	symmetry   string
+	minimal    bool
+	genFormat  string
+	solutions  string
+	pageSize   string
+	perPage    int
+	candidates bool
+	cellSize   int
+	shade      bool
+	captions   bool
+
+	patternFile     string
+	patternAttempts int
 )
 
-const (
-	// difficultyMin is the lowest acceptable solver difficulty score (0–100).
-	// Puzzles scoring below this threshold are too easy and are discarded.
-	difficultyMin = 67
-	// difficultyMax is the highest acceptable solver difficulty score (0–100).
-	// Puzzles scoring above this threshold are considered unsolvable by normal
-	// human techniques and are discarded.
-	difficultyMax = 100
-	// difficultyMaxRetries caps how many consecutive out-of-range puzzles the
-	// generator will discard before giving up, preventing an infinite loop when
-	// the requested clue count cannot yield puzzles in the target difficulty range.
-	difficultyMaxRetries = 50
-)
+// defaultMaxRetries caps how many consecutive out-of-range puzzles the
+// generator will discard before giving up, preventing an infinite loop when
+// the requested clue count cannot yield puzzles in the target difficulty range.
+// At the clue counts generator.CategoryClues picks, about one candidate in
+// eighteen is Expert, the rarest tier, so the cap is almost never reached
+// there.
+const defaultMaxRetries = 500
 
 func init() {
 	genCmd := &cobra.Command{
@@ -71,17 +65,124 @@ func init() {
 Examples:
   sudoku gen --clueCount 40
   sudoku gen -n 5 --clueCount 30
-  sudoku gen --clueCount 20 --timeout 15s
-  sudoku gen --type jigsaw -n 4 -o puzzles.html`,
+  sudoku gen --clueCount 22 --timeout 15s
+  sudoku gen -n 10 --difficulty expert
+  sudoku gen --clueCount 24:28 --difficulty hard
+  sudoku gen -n 20 --clueCount 36 --difficulty 1.2:2.3
+  sudoku gen --clueCount 24 --difficulty medium:expert --max-retries 200
+  sudoku gen --type jigsaw -n 4 -o puzzles.html
+  sudoku gen -n 100 --clueCount 24 --difficulty hard --workers 8 -o book.html
+  sudoku gen -n 10 --seed 1234 --type jigsaw -o jigsaw.html
+  sudoku gen -n 6 --clueCount 24:26 --symmetry rotational -o symmetric.html
+  sudoku gen -n 5 --clueCount 24 --minimal
+  sudoku gen -n 4 --pattern heart.txt -o hearts.html
+  sudoku gen -n 50 --difficulty hard --format jsonl -o hard.jsonl
+  sudoku gen -n 20 -o puzzles.csv --solutions file
+  sudoku gen -n 12 --type jigsaw --solutions section -o book.html
+  sudoku gen -n 60 --per-page 6 --page-size a5 -o pocket.html
+  sudoku gen -n 10 --format html-interactive -o play.html
+  sudoku gen -n 24 --type jigsaw --per-page 4 --page-size letter -o book.pdf
+  sudoku gen -n 3 --type jigsaw --solutions file -o jigsaw.svg
+  sudoku gen -n 5 --type jigsaw --shade --captions --cell-size 64 -o post.png
+
+--difficulty keeps only puzzles rated within a score, range of scores or
+tier, discarding others up to --max-retries times per puzzle. Unless
+--clueCount is given too, the clue count is drawn from the range where the
+requested tiers are most common; an explicit clue count far from it may
+need many more retries.
+
+Symmetric clue patterns are removed a whole orbit of cells at a time, so a
+single clue count may be exceeded by up to one orbit less one cell; with a
+range any count inside it is accepted.
+
+With --minimal every clue that can go is removed and only puzzles where no
+single given is redundant are kept, so the clue count is a guide: the output
+shows how far each puzzle ended up from it.
+
+A --pattern file fixes where the givens go: nine rows of nine cells with 'X'
+for a given and '.' for an empty cell (spaces, blank lines and '#' comments
+are ignored). Candidate solution grids are tried until one gives the pattern
+a unique solution, up to --pattern-attempts per puzzle. Patterns with fewer
+than about 22 givens, or with empty rows or columns, may need a larger budget
+or may have no unique puzzle at all.
+
+The output format is chosen with --format or from the extension of --output
+(.txt, .json, .jsonl, .csv, .html, .pdf, .svg or .png); an output file with any other extension
+gets HTML. With json or jsonl each puzzle is written as a record holding the
+puzzle, solution, clue count, difficulty, seed, board type and, for jigsaw,
+the region map: a JSON array, or one record per line. 'sudoku solve' and
+'sudoku play' read these records back.
+
+Every format records the seed of the run: a header line in text, a field of
+each JSON and CSV record, the footer of HTML and PDF pages and the
+description of SVG and PNG images. Running again with that --seed and the
+same options gives the same puzzles, so puzzle N comes back with -n N or
+more, whatever --workers is.
+
+--solutions places the solutions: none, inline after each puzzle, in a
+section after all the puzzles, or in a separate file named like the output
+with "-solutions" added (book.html gives book-solutions.html). Text, JSON and
+CSV include them inline by default; HTML leaves them out; PDF puts them in
+an answer key at the back. In HTML and PDF, inline solutions follow
+each puzzle at full size, while the section and the separate file are an
+answer key of small grids, several to a page, each labelled with its
+puzzle number.
+
+PDF and HTML booklets lay out --per-page puzzles (1, 2, 4 or 6) to each
+--page-size page (a4, a5 or letter), easiest first, with grids scaled to
+fit; a PDF also opens with a title page.
+
+html-interactive writes a single HTML page, playable offline in a browser:
+click a cell and type, pencil in marks, undo, and check the grid against
+the solution, which the page carries in obfuscated form. With --solutions
+other than none it also offers to show the solution. It has no file
+extension of its own, so choose it with --format.
+
+--theme styles HTML booklets with a built-in theme or one of your own from
+the user themes directory or --theme-dir; 'sudoku themes' describes them.
+
+--template replaces the built-in HTML template with your own, written for
+Go's html/template. It gets the same data: .TitlePrefix, .BodyFont,
+.HeadingFont, .ThemeClass, .ExtraCSS, .Header, .Footer, .Seed, .Generated
+(a time.Time), .PerPage, .PageSize, and the puzzles as .PuzzlePages,
+grouped into printed pages as .Sheets, with the answer key in .AnswerPages.
+Each puzzle has .Title, .PuzzleNumber, .Difficulty, .GridHTML,
+.SolutionHTML, .Clues, .Seed and .BoardType.
+
+SVG and PNG write one image per puzzle, numbered after the output file
+(puzzles.svg gives puzzles-001.svg, puzzles-002.svg, ...). Both write the
+solutions to images of their own (puzzles-solutions-001.svg, ...) unless
+--solutions is none; on standard output they write the puzzles alone.
+--cell-size sets the width of a cell in pixels, --shade tints each region a
+pastel colour, --captions adds the puzzle number and difficulty above the
+grid, and --candidates pencils in the candidates of every empty cell.`,
 		RunE: runGen,
 	}
 
 	genCmd.Flags().IntVarP(&numPuzzles, "number", "n", 1, "Number of puzzles to generate")
 	genCmd.Flags().StringVarP(&clueCount, "clueCount", "c", fmt.Sprintf("%d", generator.DefaultClueCount), "Number of clues 17-80 or range like 28:32")
-	genCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file (e.g., puzzles.html)")
-	genCmd.Flags().StringVarP(&theme, "theme", "t", "", "Theme for HTML output (e.g., princess-lily)")
+	genCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file; its extension picks the format (e.g., puzzles.html, puzzles.csv)")
+	genCmd.Flags().StringVarP(&themeName, "theme", "t", "", "Theme for HTML output (e.g., princess-lily); see 'sudoku themes list'")
+	genCmd.Flags().StringVar(&themeDir, "theme-dir", "", "Extra directory of HTML themes")
+	genCmd.Flags().StringVar(&tmplFile, "template", "", "HTML template file to use instead of the built-in one")
 	genCmd.Flags().StringVar(&boardType, "type", "standard", "Board type: standard or jigsaw")
 	genCmd.Flags().DurationVar(&timeout, "timeout", 10*time.Second, "Generation timeout per puzzle")
+	genCmd.Flags().StringVarP(&difficulty, "difficulty", "d", "", "Difficulty score (1.2-7.0), range like 2.5:4.0, or tier (easy, medium, hard, expert, diabolical); default any")
+	genCmd.Flags().IntVar(&maxRetries, "max-retries", defaultMaxRetries, "Maximum out-of-range candidates to discard per puzzle")
+	genCmd.Flags().IntVarP(&workers, "workers", "w", runtime.NumCPU(), "Number of puzzles to generate in parallel")
+	genCmd.Flags().Int64Var(&seed, "seed", 0, "Seed for reproducible output (0 = random; the seed used is printed)")
+	genCmd.Flags().BoolVar(&minimal, "minimal", false, "Only keep minimal puzzles, where no given can be removed")
+	genCmd.Flags().StringVar(&patternFile, "pattern", "", "File with a 9x9 X/. mask of the cells that must hold givens")
+	genCmd.Flags().IntVar(&patternAttempts, "pattern-attempts", generator.DefaultPatternAttempts, "Candidate grids to try against --pattern per puzzle")
+	genCmd.Flags().StringVar(&genFormat, "format", "", "Output format: "+strings.Join(output.Names(), ", ")+" (default from the --output extension, else text)")
+	genCmd.Flags().StringVar(&solutions, "solutions", "", "Where solutions go: none, inline, section or file (default depends on the format)")
+	genCmd.Flags().StringVar(&pageSize, "page-size", "a4", "Page size for PDF and HTML output: a4, a5 or letter")
+	genCmd.Flags().IntVar(&perPage, "per-page", 1, "Puzzles per page for PDF and HTML output: 1, 2, 4 or 6")
+	genCmd.Flags().BoolVar(&candidates, "candidates", false, "Draw the candidates of empty cells in SVG and PNG output")
+	genCmd.Flags().IntVar(&cellSize, "cell-size", render.DefaultCellSize, "Cell width in pixels for SVG and PNG output")
+	genCmd.Flags().BoolVar(&shade, "shade", false, "Shade each region a pastel colour in SVG and PNG output")
+	genCmd.Flags().BoolVar(&captions, "captions", false, "Print the puzzle number and difficulty above SVG and PNG images")
+	genCmd.Flags().StringVar(&symmetry, "symmetry", "none", "Clue symmetry: none, rotational, rotational90, horizontal, vertical, diagonal, anti-diagonal or dihedral")
 
 	rootCmd.AddCommand(genCmd)
 }
@@ -117,148 +218,60 @@ func parseClueCountRange(s string) (min, max int, err error) {
 	return 0, 0, fmt.Errorf("invalid clue count format: %s (use format like '32' or '28:32')", s)
 }
 
-// boardToHTML converts a board to a safe HTML table for embedding in the template.
-// For jigsaw layouts, each cell receives directional border classes (border-top,
-// border-right, border-bottom, border-left) wherever the cell abuts a different
-// region — these produce bold printed region boundaries.
-// For standard layouts the existing nth-child CSS handles thick 3×3-box borders.
-func boardToHTML(b *board.Board) template.HTML {
-	layout := b.Layout()
-	isJigsaw := layout.Type == "jigsaw"
-
-	var sb strings.Builder
-	gridClass := "sudoku-grid"
-	if isJigsaw {
-		gridClass += " jigsaw-grid"
-	}
-	fmt.Fprintf(&sb, `<div class="%s"><table>`, gridClass)
-	for row := range 9 {
-		sb.WriteString("<tr>")
-		for col := range 9 {
-			pos := board.MakePos(row, col)
-			val := b.Get(pos)
-			region := layout.PosToRegion[pos]
-
-			// Build CSS class list for this cell.
-			var classes []string
-			if val == board.EmptyCell {
-				classes = append(classes, "empty")
-			}
-			if isJigsaw {
-				// Add a directional border class for each edge where the
-				// adjacent cell belongs to a different region (or is outside
-				// the grid, which also marks a boundary).
-				if row == 0 || layout.PosToRegion[board.MakePos(row-1, col)] != region {
-					classes = append(classes, "border-top")
-				}
-				if row == 8 || layout.PosToRegion[board.MakePos(row+1, col)] != region {
-					classes = append(classes, "border-bottom")
-				}
-				if col == 0 || layout.PosToRegion[board.MakePos(row, col-1)] != region {
-					classes = append(classes, "border-left")
-				}
-				if col == 8 || layout.PosToRegion[board.MakePos(row, col+1)] != region {
-					classes = append(classes, "border-right")
-				}
-			}
-
-			classAttr := ""
-			if len(classes) > 0 {
-				classAttr = ` class="` + strings.Join(classes, " ") + `"`
-			}
-
-			if val == board.EmptyCell {
-				fmt.Fprintf(&sb, "<td%s></td>", classAttr)
-			} else {
-				fmt.Fprintf(&sb, "<td%s>%d</td>", classAttr, val)
-			}
+// resolveOutput picks the output format from --format or, failing that,
+// from the extension of --output, and returns the file to write ("" for
+// stdout). An output file with no recognised extension gets HTML, as it
+// always has.
+func resolveOutput(name, filename string) (output.Format, string, error) {
+	// Replace * in the name with a default, as earlier releases did.
+	filename = strings.ReplaceAll(filename, "*", "puzzles")
+
+	if name != "" {
+		f, ok := output.Lookup(name)
+		if !ok {
+			return output.Format{}, "", fmt.Errorf("unknown format %q: must be one of %s", name, strings.Join(output.Names(), ", "))
 		}
-		sb.WriteString("</tr>")
+		return f, filename, nil
+	}
+	if filename == "" {
+		f, _ := output.Lookup("text")
+		return f, "", nil
 	}
-	sb.WriteString("</table></div>")
-	return template.HTML(sb.String())
+	if f, ok := output.ForPath(filename); ok {
+		return f, filename, nil
+	}
+	f, _ := output.Lookup("html")
+	return f, filename + f.Extensions[0], nil
 }
 
-// generateHTML creates an HTML file with puzzles using templates.
-func generateHTML(filename string, puzzles []*board.Board, difficulties []int, theme string) error {
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("failed to create HTML file: %w", err)
+// outputFiles describes the files written for path: path itself, or the
+// range of numbered files of a PerPuzzle format.
+func outputFiles(f output.Format, path string, n int) string {
+	if !f.PerPuzzle {
+		return path
 	}
-	defer file.Close()
-
-	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
-
-	// Determine theme-specific values.
-	var titlePrefix, bodyFont, headingFont, themeClass string
-	var titleMessages []string
-	switch strings.ToLower(theme) {
-	case "princess-lily":
-		titlePrefix = "Princess Lily's Puzzle"
-		bodyFont = "'Georgia', 'Times New Roman', serif"
-		headingFont = "'Playfair Display', 'Georgia', serif"
-		themeClass = "princess-theme"
-		titleMessages = []string{
-			"Beautiful Princess Lily's Puzzle",
-			"Lovely Princess Lily's Puzzle",
-			"Adorable Princess Lily's Puzzle",
-			"Enchanting Princess Lily's Puzzle",
-			"Dazzling Princess Lily's Puzzle",
-			"Glorious Princess Lily's Puzzle",
-			"Radiant Princess Lily's Puzzle",
-			"Charming Princess Lily's Puzzle",
-			"Darling Princess Lily's Puzzle",
-			"Magnificent Princess Lily's Puzzle",
-			"Sweetest Princess Lily's Puzzle",
-			"Wonderful Princess Lily's Puzzle",
-			"Angelic Princess Lily's Puzzle",
-			"Angel Cat Lily's Puzzle",
-		}
-	default:
-		titlePrefix = "Sudoku Puzzle"
-		bodyFont = "Arial, sans-serif"
-		headingFont = "Arial, sans-serif"
-		themeClass = ""
-	}
-
-	// Pre-render each puzzle board into HTML so the template stays logic-free.
-	pages := make([]PuzzlePage, len(puzzles))
-	for i, p := range puzzles {
-		title := titlePrefix
-		if len(titleMessages) > 0 {
-			title = titleMessages[rng.Intn(len(titleMessages))]
-		}
-		pages[i] = PuzzlePage{
-			Title:        title,
-			PuzzleNumber: i + 1,
-			Difficulty:   difficulties[i],
-			GridHTML:     boardToHTML(p),
-		}
+	if n == 1 {
+		return output.PuzzlePath(path, 1)
 	}
+	return output.PuzzlePath(path, 1) + " to " + output.PuzzlePath(path, n)
+}
 
-	tmpl, err := template.ParseFS(templateFS, "templates/puzzles.html")
+// readPattern loads a clue pattern file.
+func readPattern(name string) (*generator.Pattern, error) {
+	file, err := os.Open(name)
 	if err != nil {
-		return fmt.Errorf("failed to parse template: %w", err)
-	}
-
-	data := TemplateData{
-		TitlePrefix: titlePrefix,
-		BodyFont:    bodyFont,
-		HeadingFont: headingFont,
-		ThemeClass:  themeClass,
-		PuzzlePages: pages,
+		return nil, fmt.Errorf("failed to open pattern file: %w", err)
 	}
+	defer file.Close()
 
-	if err := tmpl.Execute(file, data); err != nil {
-		return fmt.Errorf("failed to execute template: %w", err)
+	pattern, err := generator.ParsePattern(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
 	}
-
-	return nil
+	return pattern, nil
 }
 
 func runGen(cmd *cobra.Command, args []string) error {
-	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
-
 	// Validate --type early before entering the generation loop.
 	switch boardType {
 	case "jigsaw", "standard", "":
@@ -266,6 +279,59 @@ func runGen(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("unknown board type %q: must be standard or jigsaw", boardType)
 	}
 
+	// Parse the difficulty range; an empty range accepts any rating.
+	diffRange, err := solver.ParseScoreRange(difficulty)
+	if err != nil {
+		return err
+	}
+	// Without --clueCount, draw the clue count from where the requested
+	// tiers are most common.
+	if difficulty != "" && !cmd.Flags().Changed("clueCount") {
+		lo, hi := generator.RangeClues(diffRange, boardType)
+		clueCount = fmt.Sprintf("%d:%d", lo, hi)
+	}
+	if maxRetries < 0 {
+		return fmt.Errorf("max retries (%d) cannot be negative", maxRetries)
+	}
+	if workers < 1 {
+		return fmt.Errorf("workers (%d) must be at least 1", workers)
+	}
+	sym, err := generator.ParseSymmetry(symmetry)
+	if err != nil {
+		return err
+	}
+	format, filename, err := resolveOutput(genFormat, outputFile)
+	if err != nil {
+		return err
+	}
+	placement, err := output.ParseSolutions(solutions)
+	if err != nil {
+		return err
+	}
+	paper, err := output.ParsePageSize(pageSize)
+	if err != nil {
+		return err
+	}
+	if !output.ValidPerPage(perPage) {
+		return fmt.Errorf("puzzles per page (%d) must be 1, 2, 4 or 6", perPage)
+	}
+	if cellSize < 8 || cellSize > 512 {
+		return fmt.Errorf("cell size (%d) must be between 8 and 512 pixels", cellSize)
+	}
+	if tmplFile != "" && format.Name != "html" {
+		return fmt.Errorf("--template needs html output, not %s", format.Name)
+	}
+	var bookTheme *theme.Theme
+	if format.Themed {
+		dirs, err := themeDirs()
+		if err != nil {
+			return err
+		}
+		if bookTheme, err = theme.Find(themeName, dirs...); err != nil {
+			return err
+		}
+	}
+
 	// Parse clue count range
 	minClues, maxClues, err := parseClueCountRange(clueCount)
 	if err != nil {
@@ -280,112 +346,174 @@ func runGen(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("clue count max (%d) must be between %d and %d", maxClues, generator.MinValidClueCount, generator.MaxValidClueCount)
 	}
 
-	// Prepare for HTML output if output file is specified
-	var puzzles []*board.Board
-	var difficulties []int
-	outputHTML := outputFile != ""
-
-	// Generate puzzles
-	for i := 0; i < numPuzzles; i++ {
-		// Randomly select clue count from range if it's a range
-		selectedClueCount := minClues
-		if maxClues > minClues {
-			selectedClueCount = minClues + rng.Intn(maxClues-minClues+1)
+	// A pattern fixes the givens, so it replaces the digging options.
+	if patternFile != "" {
+		for _, name := range []string{"clueCount", "symmetry", "minimal"} {
+			if cmd.Flags().Changed(name) {
+				return fmt.Errorf("--pattern cannot be combined with --%s", name)
+			}
 		}
-
-		// Generate a fresh layout per puzzle so each jigsaw has unique regions.
-		var layout *board.Layout
-		if boardType == "jigsaw" {
-			layout = board.RandomJigsawLayout(rng)
-		} else {
-			layout = board.StandardLayout()
+		if patternAttempts < 1 {
+			return fmt.Errorf("pattern attempts (%d) must be at least 1", patternAttempts)
 		}
+	}
 
-		opts := generator.DefaultOptions(selectedClueCount)
-		opts.Timeout = timeout
-		opts.Layout = layout
-		gen := generator.New(opts)
+	// Flags are valid; failures from here on are not usage errors.
+	cmd.SilenceUsage = true
 
-		puzzle, solution, err := gen.Generate()
-		if err != nil {
-			return fmt.Errorf("generation failed: %w", err)
+	var pattern *generator.Pattern
+	if patternFile != "" {
+		if pattern, err = readPattern(patternFile); err != nil {
+			return err
 		}
+	}
+	var tmpl *output.Template
+	if tmplFile != "" {
+		if tmpl, err = output.ParseTemplate(tmplFile); err != nil {
+			return err
+		}
+	}
 
-		// Calculate difficulty, retrying generation if the puzzle falls outside
-		// the accepted range. The retry cap prevents an infinite loop in cases
-		// where the requested clue count cannot produce puzzles in range.
-		difficulty := solver.Difficulty(puzzle)
-		retries := 0
-		for (difficulty < difficultyMin || difficulty > difficultyMax) && retries < difficultyMaxRetries {
-			puzzle, solution, err = gen.Generate()
-			if err != nil {
-				return fmt.Errorf("generation failed during difficulty retry: %w", err)
+	// Every random choice for puzzle i (clue count, layout, solution and
+	// digging order) comes from stream i of the pool, so the run is
+	// reproducible regardless of how many workers take part.
+	// Resolve the seed up front so it can be reported and reused.
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	pool := &generator.Pool{
+		Workers:    workers,
+		Seed:       seed,
+		Timeout:    timeout,
+		MaxRetries: maxRetries,
+		Accept: func(r solver.Rating) bool {
+			return diffRange.Contains(r.Score)
+		},
+		Options: func(_ int, rng *rand.Rand) *generator.Options {
+			// Randomly select clue count from range if it's a range
+			selectedClueCount := minClues
+			if maxClues > minClues {
+				selectedClueCount = minClues + rng.Intn(maxClues-minClues+1)
 			}
-			difficulty = solver.Difficulty(puzzle)
-			retries++
-		}
-		if difficulty < difficultyMin || difficulty > difficultyMax {
-			return fmt.Errorf("could not generate puzzle with difficulty in [%d, %d] after %d attempts", difficultyMin, difficultyMax, difficultyMaxRetries)
-		}
 
-		if outputHTML {
-			// Store puzzles for HTML output
-			puzzles = append(puzzles, puzzle)
-			difficulties = append(difficulties, difficulty)
-		} else {
-			// Print to console
-			fmt.Printf("Puzzle #%d (Clues: %d, Difficulty: %d):\n", i+1, selectedClueCount, difficulty)
-			fmt.Println(puzzle.Format())
-			fmt.Println("\nSolution:")
-			fmt.Println(solution.Format())
-			fmt.Println()
-		}
+			// Generate a fresh layout per puzzle so each jigsaw has unique regions.
+			var layout *board.Layout
+			if boardType == "jigsaw" {
+				layout = board.RandomJigsawLayout(rng)
+			} else {
+				layout = board.StandardLayout()
+			}
+
+			// The pool's per-puzzle timeout bounds every attempt, including
+			// difficulty retries, so the generator needs none of its own.
+			opts := generator.DefaultOptions(selectedClueCount)
+			opts.Timeout = 0
+			opts.Layout = layout
+			opts.Symmetry = sym
+			opts.Minimal = minimal
+			opts.Pattern = pattern
+			opts.PatternAttempts = patternAttempts
+			// Symmetric digging may stop short of the selected count; any
+			// count inside a requested range is fine.
+			if maxClues > minClues {
+				opts.MaxClueCount = maxClues
+			}
+			return opts
+		},
 	}
 
-	// Write HTML file if output was specified
-	if outputHTML {
-		// Sort puzzles by difficulty (ascending order)
-		type puzzleWithDifficulty struct {
-			puzzle     *board.Board
-			difficulty int
-		}
-		puzzleList := make([]puzzleWithDifficulty, len(puzzles))
-		for i := 0; i < len(puzzles); i++ {
-			puzzleList[i] = puzzleWithDifficulty{
-				puzzle:     puzzles[i],
-				difficulty: difficulties[i],
+	ctx, cancel := context.WithCancel(cmd.Context())
+	defer cancel()
+
+	// Results arrive in completion order; hold early ones back so puzzles are
+	// printed and stored in index order.
+	results := make([]*generator.Result, numPuzzles)
+	next := 0
+
+	// discarded counts generated candidates rejected for being out of range.
+	discarded := 0
+
+	w, err := output.Open(format, filename, output.Options{
+		Solutions:  placement,
+		Seed:       seed,
+		Theme:      bookTheme,
+		Template:   tmpl,
+		MinClues:   minClues,
+		MaxClues:   maxClues,
+		Annotate:   minimal,
+		PageSize:   paper,
+		PerPage:    perPage,
+		Candidates: candidates,
+		CellSize:   cellSize,
+		Shade:      shade,
+		Captions:   captions,
+	})
+	if err != nil {
+		return err
+	}
+	// Output is only finished once every puzzle is in; a failure leaves no
+	// half-written file behind.
+	defer w.Abort()
+
+	for res := range pool.Run(ctx, numPuzzles) {
+		discarded += res.Discarded
+		if res.Err != nil {
+			cancel()
+			switch {
+			case errors.Is(res.Err, generator.ErrTimeout):
+				return fmt.Errorf("puzzle #%d was not generated within %s (discarded %d candidate(s))", res.Index+1, timeout, res.Discarded)
+			case errors.Is(res.Err, generator.ErrPatternUnsatisfied):
+				return fmt.Errorf("puzzle #%d: none of %d candidate grids gave pattern %s a unique solution; raise --pattern-attempts or add givens", res.Index+1, patternAttempts, patternFile)
+			case errors.Is(res.Err, generator.ErrDifficultyOutOfRange):
+				return fmt.Errorf("could not generate puzzle with difficulty %s from %s clues after %d attempts; try another --clueCount or raise --max-retries", diffRange, clueCount, res.Discarded)
 			}
-		}
-		sort.Slice(puzzleList, func(i, j int) bool {
-			return puzzleList[i].difficulty < puzzleList[j].difficulty
-		})
-
-		// Extract sorted puzzles and difficulties
-		sortedPuzzles := make([]*board.Board, len(puzzleList))
-		sortedDifficulties := make([]int, len(puzzleList))
-		for i := range puzzleList {
-			sortedPuzzles[i] = puzzleList[i].puzzle
-			sortedDifficulties[i] = puzzleList[i].difficulty
+			return fmt.Errorf("generation failed: %w", res.Err)
 		}
 
-		// Expand wildcards in filename if needed
-		filename := outputFile
-		if strings.Contains(filename, "*") {
-			// Replace * with index for multiple files, or use a default name
-			// For now, just use the filename as-is, replacing * with puzzles
-			filename = strings.ReplaceAll(filename, "*", "puzzles")
+		results[res.Index] = &res
+		for ; next < numPuzzles && results[next] != nil; next++ {
+			r := results[next]
+			err := w.Write(output.Puzzle{
+				Number:   next + 1,
+				Seed:     r.Seed,
+				Puzzle:   r.Puzzle,
+				Solution: r.Solution,
+				Rating:   r.Rating,
+			})
+			if err != nil {
+				cancel()
+				return fmt.Errorf("failed to write puzzle #%d: %w", next+1, err)
+			}
 		}
+	}
+	if next < numPuzzles {
+		return fmt.Errorf("generation cancelled: %w", context.Cause(ctx))
+	}
 
-		// Ensure .html extension
-		if filepath.Ext(filename) != ".html" {
-			filename = filename + ".html"
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to write %s output: %w", format.Name, err)
+	}
+	if filename != "" {
+		fmt.Printf("Generated %d puzzle(s) in %s (seed %d)\n", numPuzzles, outputFiles(format, filename, numPuzzles), seed)
+		if format.Placement(placement) == output.SolutionsFile {
+			fmt.Printf("Solutions in %s\n", outputFiles(format, output.SolutionsPath(filename), numPuzzles))
 		}
+	}
 
-		err := generateHTML(filename, sortedPuzzles, sortedDifficulties, theme)
-		if err != nil {
-			return fmt.Errorf("failed to write HTML file: %w", err)
+	// Minimal puzzles settle wherever their minimal form lands, so summarise
+	// how that compares to what was asked for.
+	if minimal {
+		fewest, most := board.CellCount, 0
+		for _, r := range results {
+			fewest, most = min(fewest, r.Puzzle.ClueCount()), max(most, r.Puzzle.ClueCount())
 		}
-		fmt.Printf("Generated %d puzzle(s) in %s\n", numPuzzles, filename)
+		fmt.Fprintf(os.Stderr, "Minimal puzzles have %d to %d clues (requested %s)\n", fewest, most, clueCount)
+	}
+
+	// Report rejected candidates on stderr so console output stays clean.
+	if discarded > 0 {
+		fmt.Fprintf(os.Stderr, "Discarded %d candidate(s) outside difficulty %s\n", discarded, diffRange)
 	}
 
 	return nil
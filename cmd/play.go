@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/rybkr/sudoku/internal/board"
+	"github.com/rybkr/sudoku/internal/generator"
+	"github.com/rybkr/sudoku/internal/play"
+	"github.com/rybkr/sudoku/internal/solver"
+)
+
+var (
+	playFile       string
+	playIndex      int
+	playType       string
+	playLayout     string
+	playClueCount  string
+	playDifficulty string
+	playSeed       int64
+)
+
+func init() {
+	playCmd := &cobra.Command{
+		Use:   "play [puzzle]",
+		Short: "Play Sudoku in the terminal",
+		Long: `Play a Sudoku puzzle full screen in the terminal.
+
+The puzzle is given as an 81-character string, read from a file with --file
+(the first puzzle, or the one chosen with --index), or generated when neither
+is supplied. Files may hold one puzzle per line or JSON records as written by
+'sudoku gen --format json'. Jigsaw puzzles need a region map as in
+'sudoku solve'.
+
+Move with the arrow keys or h/j/k/l and type 1-9 to fill a cell. Press p to
+switch to pencil marks (or type shift+1-9), 0, '.' or Delete to clear, u and r
+to undo and redo, ? for a hint and q to quit. Repeated digits are shown in red.
+
+Examples:
+  sudoku play
+  sudoku play --difficulty hard --clueCount 24:26
+  sudoku play --type jigsaw --seed 7
+  sudoku play -f puzzles.txt --index 3
+  sudoku play 53..7....6..195....98....6.8...6...34..8.3..17...2...6.6....28....419..5....8..79`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runPlay,
+	}
+
+	playCmd.Flags().StringVarP(&playFile, "file", "f", "", "File with one puzzle per line, or JSON records")
+	playCmd.Flags().IntVar(&playIndex, "index", 1, "Which puzzle in --file to play, from 1")
+	playCmd.Flags().StringVar(&playType, "type", "standard", "Board type: standard or jigsaw")
+	playCmd.Flags().StringVar(&playLayout, "layout", "", "Region map for jigsaw puzzles (81 digits 0-8)")
+	playCmd.Flags().StringVarP(&playClueCount, "clueCount", "c", "26:30", "Clues for a generated puzzle, 17-80 or range like 28:32")
+	playCmd.Flags().StringVarP(&playDifficulty, "difficulty", "d", "", "Difficulty of a generated puzzle: score, range or tier")
+	playCmd.Flags().Int64Var(&playSeed, "seed", 0, "Seed for a generated puzzle (0 = random)")
+
+	rootCmd.AddCommand(playCmd)
+}
+
+func runPlay(cmd *cobra.Command, args []string) error {
+	switch playType {
+	case "jigsaw", "standard", "":
+	default:
+		return fmt.Errorf("unknown board type %q: must be standard or jigsaw", playType)
+	}
+	if len(args) > 0 && playFile != "" {
+		return fmt.Errorf("give either a puzzle or --file, not both")
+	}
+
+	var puzzle *board.Board
+	var err error
+	switch {
+	case len(args) > 0:
+		cmd.SilenceUsage = true
+		puzzle, err = parsePuzzle(puzzleInput{source: "arg 1", puzzle: strings.TrimSpace(args[0])}, playType, playLayout)
+	case playFile != "":
+		cmd.SilenceUsage = true
+		puzzle, err = loadPlayPuzzle(playFile, playIndex)
+	default:
+		puzzle, err = generatePlayPuzzle(cmd)
+	}
+	if err != nil {
+		return err
+	}
+
+	game, err := play.NewGame(puzzle)
+	if err != nil {
+		return err
+	}
+	if err := play.Play(cmd.Context(), game); err != nil {
+		return err
+	}
+
+	if game.Solved() {
+		fmt.Printf("Solved in %s.\n", play.FormatDuration(game.Elapsed()))
+		return nil
+	}
+	// Print the puzzle so an unfinished game can be started again.
+	fmt.Printf("Stopped after %s with %d cell(s) left.\n", play.FormatDuration(game.Elapsed()), game.Remaining())
+	line := puzzle.String()
+	if puzzle.Layout().Type == "jigsaw" {
+		line += " " + puzzle.Layout().String()
+	}
+	fmt.Println("Puzzle:", line)
+	return nil
+}
+
+// loadPlayPuzzle reads the index-th puzzle (from 1) from a puzzle file.
+func loadPlayPuzzle(name string, index int) (*board.Board, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open puzzle file: %w", err)
+	}
+	defer file.Close()
+
+	inputs, err := readPuzzleLines(file, name)
+	if err != nil {
+		return nil, err
+	}
+	if index < 1 || index > len(inputs) {
+		return nil, fmt.Errorf("%s has %d puzzle(s); --index %d is out of range", name, len(inputs), index)
+	}
+	in := inputs[index-1]
+	puzzle, err := parsePuzzle(in, playType, playLayout)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", in.source, err)
+	}
+	return puzzle, nil
+}
+
+// generatePlayPuzzle generates a puzzle from the play flags.
+func generatePlayPuzzle(cmd *cobra.Command) (*board.Board, error) {
+	minClues, maxClues, err := parseClueCountRange(playClueCount)
+	if err != nil {
+		return nil, err
+	}
+	if minClues < generator.MinValidClueCount || maxClues > generator.MaxValidClueCount {
+		return nil, fmt.Errorf("clue count must be between %d and %d", generator.MinValidClueCount, generator.MaxValidClueCount)
+	}
+	diffRange, err := solver.ParseScoreRange(playDifficulty)
+	if err != nil {
+		return nil, err
+	}
+	cmd.SilenceUsage = true
+
+	pool := &generator.Pool{
+		Workers:    1,
+		Seed:       playSeed,
+		Timeout:    30 * time.Second,
+		MaxRetries: defaultMaxRetries,
+		Accept: func(r solver.Rating) bool {
+			return diffRange.Contains(r.Score)
+		},
+		Options: func(_ int, rng *rand.Rand) *generator.Options {
+			opts := generator.DefaultOptions(minClues + rng.Intn(maxClues-minClues+1))
+			opts.Timeout = 0
+			if playType == "jigsaw" {
+				opts.Layout = board.RandomJigsawLayout(rng)
+			}
+			return opts
+		},
+	}
+
+	res := <-pool.Run(cmd.Context(), 1)
+	switch {
+	case res.Err == nil && res.Puzzle != nil:
+		return res.Puzzle, nil
+	case errors.Is(res.Err, generator.ErrDifficultyOutOfRange):
+		return nil, fmt.Errorf("could not generate puzzle with difficulty %s after %d attempts", diffRange, res.Discarded)
+	case res.Err != nil:
+		return nil, fmt.Errorf("generation failed: %w", res.Err)
+	}
+	return nil, fmt.Errorf("generation cancelled: %w", cmd.Context().Err())
+}
@@ -1,19 +1,28 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"github.com/spf13/cobra"
 	"os"
+	"os/signal"
+
+	"github.com/spf13/cobra"
 )
 
 var rootCmd = &cobra.Command{
-	Use:   "sudoku",
-	Short: "A modern Sudoku CLI for generating and solving puzzles",
-	Long:  `Sudoku is a modern command-line tool for generating and solving Sudoku puzzles with customizable difficulty and reproducible results.`,
+	Use:           "sudoku",
+	Short:         "A modern Sudoku CLI for generating and solving puzzles",
+	Long:          `Sudoku is a modern command-line tool for generating and solving Sudoku puzzles with customizable difficulty and reproducible results.`,
+	SilenceErrors: true,
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	// Cancel the command's context on Ctrl-C so long-running generation and
+	// solving stop promptly.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		fmt.Println("Error:", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/rybkr/sudoku/internal/server"
+)
+
+var (
+	serveAddr          string
+	serveTimeout       time.Duration
+	serveMaxConcurrent int
+)
+
+func init() {
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the generator and solver over HTTP",
+		Long: `Run an HTTP server with a JSON API for generating, solving, validating,
+rating and hinting puzzles.
+
+Endpoints (all POST with a JSON body):
+  /generate  {"clues": 28, "difficulty": "hard", "type": "jigsaw", "seed": 42}
+  /solve     {"puzzle": "53..7....", "layout": "000111222..."}
+  /validate  {"puzzle": "..."}
+  /rate      {"puzzle": "..."}
+  /hint      {"puzzle": "..."}
+
+Boards are 81-character strings with '.' for empty cells; "layout" is only
+needed for jigsaw puzzles. Each request is limited to --timeout, and at most
+--max-concurrent requests run at once; the rest wait for a slot.
+
+Examples:
+  sudoku serve
+  sudoku serve --addr 127.0.0.1:9000 --timeout 5s --max-concurrent 4
+  curl -s -d '{"difficulty":"medium"}' localhost:8080/generate`,
+		Args: cobra.NoArgs,
+		RunE: runServe,
+	}
+
+	serveCmd.Flags().StringVar(&serveAddr, "addr", ":8080", "Address to listen on")
+	serveCmd.Flags().DurationVar(&serveTimeout, "timeout", server.DefaultRequestTimeout, "Time limit per request")
+	serveCmd.Flags().IntVar(&serveMaxConcurrent, "max-concurrent", runtime.NumCPU(), "Maximum requests processed at once")
+
+	rootCmd.AddCommand(serveCmd)
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	if serveTimeout <= 0 {
+		return fmt.Errorf("timeout (%s) must be positive", serveTimeout)
+	}
+	if serveMaxConcurrent < 1 {
+		return fmt.Errorf("max concurrent (%d) must be at least 1", serveMaxConcurrent)
+	}
+	cmd.SilenceUsage = true
+
+	srv := &http.Server{
+		Addr: serveAddr,
+		Handler: server.New(server.Config{
+			RequestTimeout: serveTimeout,
+			MaxConcurrent:  serveMaxConcurrent,
+		}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Shut down gracefully on Ctrl-C, letting running requests finish.
+	ctx := cmd.Context()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), serveTimeout)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Fprintf(os.Stderr, "Listening on %s\n", serveAddr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
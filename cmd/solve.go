@@ -0,0 +1,289 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/rybkr/sudoku/internal/board"
+	"github.com/rybkr/sudoku/internal/record"
+	"github.com/rybkr/sudoku/internal/solver"
+)
+
+var (
+	solveFiles   []string
+	solveType    string
+	solveLayout  string
+	solveFormat  string
+	solveTimeout time.Duration
+	solveEngine  string
+)
+
+// puzzleInput is a single puzzle read from the command line, a file or stdin.
+type puzzleInput struct {
+	source string // human-readable origin, e.g. "arg 1" or "puzzles.txt:3"
+	puzzle string // 81-character puzzle string
+	layout string // optional 81-character region map (jigsaw only)
+	// boardType is the type recorded with the puzzle, if any; it overrides
+	// the --type flag.
+	boardType string
+}
+
+func init() {
+	solveCmd := &cobra.Command{
+		Use:   "solve [puzzle...]",
+		Short: "Solve Sudoku puzzles",
+		Long: `Solve one or more Sudoku puzzles given as 81-character strings.
+
+Puzzles are read from the arguments, from files given with --file (one puzzle
+per line), or from stdin when neither is supplied. Use '.' or '0' for empty
+cells. Blank lines and lines starting with '#' are ignored. Files and stdin
+may instead hold JSON records as written by 'sudoku gen --format json' or
+'--format jsonl', which carry their own board type and region map.
+
+Jigsaw puzzles need a region map: an 81-character string of region indices
+0-8 in row-major order. Pass it with --layout to apply it to every puzzle, or
+append it to a line after the puzzle, separated by whitespace.
+
+Examples:
+  sudoku solve 53..7....6..195....98....6.8...6...34..8.3..17...2...6.6....28....419..5....8..79
+  sudoku solve -f puzzles.txt --format line
+  sudoku gen -n 5 --format jsonl | sudoku solve
+  cat puzzles.txt | sudoku solve --engine dlx
+  sudoku solve --type jigsaw --layout 000111222... <puzzle>`,
+		RunE: runSolve,
+	}
+
+	solveCmd.Flags().StringArrayVarP(&solveFiles, "file", "f", nil, "File with one puzzle per line or JSON records ('-' for stdin); may be repeated")
+	solveCmd.Flags().StringVar(&solveType, "type", "standard", "Board type: standard or jigsaw")
+	solveCmd.Flags().StringVar(&solveLayout, "layout", "", "Region map for jigsaw puzzles (81 digits 0-8)")
+	solveCmd.Flags().StringVar(&solveFormat, "format", "grid", "Output format: grid or line")
+	solveCmd.Flags().DurationVar(&solveTimeout, "timeout", 10*time.Second, "Solving timeout per puzzle")
+	solveCmd.Flags().StringVar(&solveEngine, "engine", "backtrack", "Search engine: backtrack or dlx")
+
+	rootCmd.AddCommand(solveCmd)
+}
+
+// readPuzzleLines reads puzzles from r: either one per line, each holding a
+// puzzle string optionally followed by a region map, or JSON records as
+// written by 'sudoku gen --format json' or 'jsonl'.
+func readPuzzleLines(r io.Reader, name string) ([]puzzleInput, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	if record.IsJSON(data) {
+		return readPuzzleRecords(bytes.NewReader(data), name)
+	}
+
+	var inputs []puzzleInput
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		in := puzzleInput{
+			source: fmt.Sprintf("%s:%d", name, lineNum),
+			puzzle: fields[0],
+		}
+		if len(fields) > 1 {
+			in.layout = fields[1]
+		}
+		inputs = append(inputs, in)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return inputs, nil
+}
+
+// readPuzzleRecords reads JSON puzzle records. Each record's own type and
+// region map take precedence over --type and --layout.
+func readPuzzleRecords(r io.Reader, name string) ([]puzzleInput, error) {
+	records, err := record.Read(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	inputs := make([]puzzleInput, len(records))
+	for i, rec := range records {
+		inputs[i] = puzzleInput{
+			source:    fmt.Sprintf("%s record %d", name, i+1),
+			puzzle:    rec.Puzzle,
+			layout:    rec.Layout,
+			boardType: rec.Type,
+		}
+		if inputs[i].boardType == "" && rec.Layout != "" {
+			inputs[i].boardType = "jigsaw"
+		}
+	}
+	return inputs, nil
+}
+
+// collectPuzzles gathers puzzles from the arguments, the --file flags and,
+// when neither is given, stdin.
+func collectPuzzles(args []string) ([]puzzleInput, error) {
+	var inputs []puzzleInput
+	for i, arg := range args {
+		inputs = append(inputs, puzzleInput{
+			source: fmt.Sprintf("arg %d", i+1),
+			puzzle: strings.TrimSpace(arg),
+		})
+	}
+
+	files := solveFiles
+	if len(args) == 0 && len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	for _, name := range files {
+		if name == "-" {
+			lines, err := readPuzzleLines(os.Stdin, "stdin")
+			if err != nil {
+				return nil, err
+			}
+			inputs = append(inputs, lines...)
+			continue
+		}
+
+		file, err := os.Open(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open puzzle file: %w", err)
+		}
+		lines, err := readPuzzleLines(file, name)
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, lines...)
+	}
+
+	return inputs, nil
+}
+
+// parsePuzzle turns a puzzleInput into a board of the given type, unless the
+// input records its own. Jigsaw puzzles use the input's own region map,
+// falling back to regionMap. Conflicting givens are reported as
+// solver.ErrInvalidPuzzle.
+func parsePuzzle(in puzzleInput, boardType, regionMap string) (*board.Board, error) {
+	if in.boardType != "" {
+		boardType = in.boardType
+	}
+	switch boardType {
+	case "jigsaw", "standard", "":
+	default:
+		return nil, fmt.Errorf("unknown board type %q: must be standard or jigsaw", boardType)
+	}
+
+	var layout *board.Layout
+	if boardType == "jigsaw" {
+		if in.layout != "" {
+			regionMap = in.layout
+		}
+		if regionMap == "" {
+			return nil, fmt.Errorf("jigsaw puzzle needs a region map (use --layout or append it to the line)")
+		}
+		var err error
+		layout, err = board.ParseLayout(regionMap)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	b, err := board.NewFromString(in.puzzle, layout)
+	if err != nil {
+		if errors.Is(err, board.ErrIllegalMove) {
+			return nil, fmt.Errorf("%w: %v", solver.ErrInvalidPuzzle, err)
+		}
+		return nil, err
+	}
+	return b, nil
+}
+
+func runSolve(cmd *cobra.Command, args []string) error {
+	// Validate flags early before reading any input.
+	switch solveType {
+	case "jigsaw", "standard", "":
+	default:
+		return fmt.Errorf("unknown board type %q: must be standard or jigsaw", solveType)
+	}
+	switch solveFormat {
+	case "grid", "line":
+	default:
+		return fmt.Errorf("unknown format %q: must be grid or line", solveFormat)
+	}
+	engine, err := parseEngine(solveEngine)
+	if err != nil {
+		return err
+	}
+
+	inputs, err := collectPuzzles(args)
+	if err != nil {
+		return err
+	}
+	if len(inputs) == 0 {
+		return fmt.Errorf("no puzzles to solve")
+	}
+
+	// Per-puzzle failures are reported as they happen; the command itself only
+	// fails at the end so that one bad puzzle does not stop the rest.
+	cmd.SilenceUsage = true
+
+	failed := 0
+	for i, in := range inputs {
+		puzzle, err := parsePuzzle(in, solveType, solveLayout)
+		if err == nil {
+			opts := solver.DefaultOptions()
+			opts.Timeout = solveTimeout
+			opts.Engine = engine
+			var solution *board.Board
+			solution, err = solver.New(puzzle, opts).SolveContext(cmd.Context())
+			if err == nil {
+				printSolution(i+1, puzzle, solution)
+				continue
+			}
+		}
+
+		failed++
+		fmt.Fprintf(os.Stderr, "Puzzle #%d (%s): %v\n", i+1, in.source, err)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d puzzle(s) could not be solved", failed, len(inputs))
+	}
+	return nil
+}
+
+// parseEngine maps an --engine flag value to a solver.Engine.
+func parseEngine(s string) (solver.Engine, error) {
+	switch strings.ToLower(s) {
+	case "backtrack", "backtracking", "":
+		return solver.Backtracking, nil
+	case "dlx", "dancing-links":
+		return solver.DancingLinks, nil
+	}
+	return 0, fmt.Errorf("unknown engine %q: must be backtrack or dlx", s)
+}
+
+// printSolution writes a solved puzzle to stdout in the selected format.
+func printSolution(n int, puzzle, solution *board.Board) {
+	if solveFormat == "line" {
+		fmt.Println(solution.String())
+		return
+	}
+	fmt.Printf("Puzzle #%d (Clues: %d):\n", n, puzzle.ClueCount())
+	fmt.Println(puzzle.Format())
+	fmt.Println("\nSolution:")
+	fmt.Println(solution.Format())
+	fmt.Println()
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/rybkr/sudoku/internal/theme"
+)
+
+var themeDir string
+
+func init() {
+	themesCmd := &cobra.Command{
+		Use:   "themes",
+		Short: "Manage the themes of HTML booklets",
+		Long: `Themes style the HTML booklets that 'sudoku gen' and 'sudoku daily' write:
+the page titles, fonts, extra CSS and an optional header and footer on every
+page.
+
+Besides the built-in themes, each directory in the themes directory of your
+user configuration (` + themeUserDirHelp() + `) or in --theme-dir holds a
+theme.json and, optionally, style.css, header.html and footer.html; a lone
+name.json file is a theme too. A theme is named after its directory or
+file, and replaces any built-in theme of the same name. theme.json may set:
+
+  description   shown by 'sudoku themes list'
+  titlePrefix   the booklet title, and the page title when there are no titles
+  titles        a list of page titles, one picked at random for each page
+  bodyFont      CSS font-family for text
+  headingFont   CSS font-family for headings
+  class         CSS class added to the page body
+  css           CSS added to the style sheet, before style.css
+  header        HTML for the top of every page, unless header.html exists
+  footer        HTML for the bottom of every page, unless footer.html exists`,
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the available themes",
+		Args:  cobra.NoArgs,
+		RunE:  runThemesList,
+	}
+	listCmd.Flags().StringVar(&themeDir, "theme-dir", "", "Extra directory of HTML themes")
+
+	themesCmd.AddCommand(listCmd)
+	rootCmd.AddCommand(themesCmd)
+}
+
+// themeUserDirHelp names the user themes directory for help text.
+func themeUserDirHelp() string {
+	if dir := theme.UserDir(); dir != "" {
+		return dir
+	}
+	return "unavailable on this system"
+}
+
+// themeDirs returns the directories to load themes from, in order: the
+// user's own, then --theme-dir, which must exist.
+func themeDirs() ([]string, error) {
+	dirs := []string{theme.UserDir()}
+	if themeDir != "" {
+		info, err := os.Stat(themeDir)
+		if err != nil {
+			return nil, fmt.Errorf("theme directory: %w", err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("theme directory %s is not a directory", themeDir)
+		}
+		dirs = append(dirs, themeDir)
+	}
+	return dirs, nil
+}
+
+func runThemesList(cmd *cobra.Command, args []string) error {
+	dirs, err := themeDirs()
+	if err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+
+	themes, err := theme.List(dirs...)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSOURCE\tDESCRIPTION")
+	for _, t := range themes {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", t.Name, t.Source, t.Description)
+	}
+	return w.Flush()
+}
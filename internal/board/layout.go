@@ -151,3 +151,76 @@ func (l *Layout) validateContiguous(region int) error {
 	}
 	return nil
 }
+
+// ParseLayout builds a Layout from an 81-character region map string.
+// Each character is the region index '0'–'8' of the corresponding cell, in
+// row-major order — the same encoding returned by Layout.String.
+// A string that describes the classic 3×3 boxes yields a "standard" layout.
+func ParseLayout(s string) (*Layout, error) {
+	if len(s) != CellCount {
+		return nil, fmt.Errorf("layout: region map must be exactly %d characters, got %d", CellCount, len(s))
+	}
+
+	var rm [CellCount]int
+	for pos := range CellCount {
+		ch := s[pos]
+		if ch < '0' || ch > '8' {
+			return nil, fmt.Errorf("layout: invalid region '%c' at position %d (must be 0–8)", ch, pos)
+		}
+		rm[pos] = int(ch - '0')
+	}
+
+	l, err := NewLayout(rm)
+	if err != nil {
+		return nil, err
+	}
+	if rm == StandardLayout().PosToRegion {
+		l.Type = "standard"
+	}
+	return l, nil
+}
+
+// String returns the region map as an 81-character string of region indices
+// '0'–'8' in row-major order. It is the inverse of ParseLayout.
+func (l *Layout) String() string {
+	var buf [CellCount]byte
+	for pos, r := range l.PosToRegion {
+		buf[pos] = '0' + byte(r)
+	}
+	return string(buf[:])
+}
+
+// Border is a set of cell sides.
+type Border uint8
+
+// Cell sides, combined in a Border.
+const (
+	BorderTop Border = 1 << iota
+	BorderRight
+	BorderBottom
+	BorderLeft
+)
+
+// Borders returns the sides of the cell at pos that lie on a region
+// boundary: next to a cell of another region, or on the edge of the grid.
+// Renderers draw these sides thick, which gives the 3×3 box lines of a
+// standard layout and the irregular outlines of a jigsaw.
+func (l *Layout) Borders(pos int) Border {
+	row, col := pos/9, pos%9
+	region := l.PosToRegion[pos]
+
+	var b Border
+	if row == 0 || l.PosToRegion[MakePos(row-1, col)] != region {
+		b |= BorderTop
+	}
+	if col == 8 || l.PosToRegion[MakePos(row, col+1)] != region {
+		b |= BorderRight
+	}
+	if row == 8 || l.PosToRegion[MakePos(row+1, col)] != region {
+		b |= BorderBottom
+	}
+	if col == 0 || l.PosToRegion[MakePos(row, col-1)] != region {
+		b |= BorderLeft
+	}
+	return b
+}
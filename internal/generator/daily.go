@@ -0,0 +1,120 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/rybkr/sudoku/internal/board"
+	"github.com/rybkr/sudoku/internal/solver"
+)
+
+// DateFormat is the layout of the date that daily puzzles are keyed by.
+const DateFormat = "2006-01-02"
+
+// Bounds on the search for a daily puzzle. They are part of the derivation:
+// changing them changes which puzzle some days get.
+const (
+	// dailyCandidates caps the candidates tried before giving up.
+	dailyCandidates = 1000
+	// dailyAttempts caps the grids dug per candidate, so that a jigsaw
+	// layout admitting no puzzle with that few clues is abandoned after a
+	// fixed amount of work rather than a timeout.
+	dailyAttempts = 20
+)
+
+// Daily is the puzzle of the day for a date, category and board type.
+type Daily struct {
+	Date     string // Date is the day in DateFormat
+	Category solver.Category
+	Type     string // Type is "standard" or "jigsaw"
+	Seed     int64  // Seed is the seed the puzzle was derived from
+	// PuzzleSeed is the seed of the stream of the chosen candidate,
+	// DeriveSeed(Seed, i) for candidate i.
+	PuzzleSeed int64
+	Puzzle     *board.Board
+	Solution   *board.Board
+	Rating     solver.Rating
+}
+
+// DailySeed derives the seed of the daily puzzle from the date's calendar
+// day, the category and the board type, by hashing "2026-10-16|hard|jigsaw"
+// with 64-bit FNV-1a. Only the year, month and day of date are used, in
+// date's own location.
+func DailySeed(date time.Time, category solver.Category, boardType string) int64 {
+	key := date.Format(DateFormat) + "|" + strings.ToLower(category.String()) + "|" + dailyType(boardType)
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	seed := int64(h.Sum64())
+	if seed == 0 {
+		seed = 1
+	}
+	return seed
+}
+
+// dailyType normalises a board type, treating "" as "standard".
+func dailyType(boardType string) string {
+	if boardType == "jigsaw" {
+		return "jigsaw"
+	}
+	return "standard"
+}
+
+// GenerateDaily generates the puzzle of the day. The puzzle, its layout and
+// its solution depend only on the date, category and board type, so every
+// caller generates the same one without sharing any state.
+//
+// Candidate i is generated from its own stream, seeded by
+// DeriveSeed(DailySeed(...), i), which picks its clue count and jigsaw
+// layout; the first candidate rated in the category is the puzzle of the
+// day. Returns ErrDifficultyOutOfRange if none is.
+func GenerateDaily(ctx context.Context, date time.Time, category solver.Category, boardType string) (*Daily, error) {
+	if category < solver.Easy || category > solver.Diabolical {
+		return nil, fmt.Errorf("unknown category %v", category)
+	}
+	boardType = dailyType(boardType)
+	seed := DailySeed(date, category, boardType)
+
+	for i := range dailyCandidates {
+		stream := DeriveSeed(seed, i)
+		rng := rand.New(rand.NewSource(stream))
+		minClues, maxClues := CategoryClues(category, boardType)
+		opts := DefaultOptions(minClues + rng.Intn(maxClues-minClues+1))
+		opts.Timeout = 0
+		opts.Attempts = dailyAttempts
+		if boardType == "jigsaw" {
+			opts.Layout = board.RandomJigsawLayout(rng)
+		}
+		opts.Seed = rng.Int63() | 1
+
+		puzzle, solution, err := New(opts).GenerateContext(ctx)
+		if errors.Is(err, ErrGenerationFailed) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		rating, err := solver.RateContext(ctx, puzzle)
+		if err != nil {
+			return nil, fmt.Errorf("rating failed: %w", err)
+		}
+		if rating.Category != category {
+			continue
+		}
+		return &Daily{
+			Date:       date.Format(DateFormat),
+			Category:   category,
+			Type:       boardType,
+			Seed:       seed,
+			PuzzleSeed: stream,
+			Puzzle:     puzzle,
+			Solution:   solution,
+			Rating:     rating,
+		}, nil
+	}
+	return nil, ErrDifficultyOutOfRange
+}
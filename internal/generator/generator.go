@@ -1,23 +1,32 @@
 package generator
 
 import (
+	"context"
 	"errors"
-	"github.com/rybkr/sudoku/internal/board"
-	"github.com/rybkr/sudoku/internal/solver"
 	"math/rand"
 	"time"
+
+	"github.com/rybkr/sudoku/internal/board"
+	"github.com/rybkr/sudoku/internal/solver"
 )
 
 const (
 	MinValidClueCount = 17
 	MaxValidClueCount = 80
 	DefaultClueCount  = 32
+
+	// solutionMaxNodes bounds the search for a solution grid before it is
+	// restarted; a typical search visits a few hundred nodes.
+	solutionMaxNodes = 20000
 )
 
 var (
 	ErrGenerationFailed = errors.New("failed to generate valid puzzle")
 	ErrInvalidClueCount = errors.New("clue count must be between 17 and 80")
 	ErrDiggingFailed    = errors.New("failed to remove proper number of clues")
+	ErrTimeout          = errors.New("generation timeout exceeded")
+
+	ErrDifficultyOutOfRange = errors.New("could not generate puzzle in the requested difficulty range")
 )
 
 // Generator creates Sudoku puzzles.
@@ -46,157 +55,218 @@ func New(options *Options) *Generator {
 // Generate creates a new Sudoku puzzle.
 // Returns the puzzle and its solution, or an error if generation fails.
 func (g *Generator) Generate() (puzzle *board.Board, solution *board.Board, err error) {
-	if g.options.ClueCount < MinValidClueCount || g.options.ClueCount > MaxValidClueCount {
+	return g.GenerateContext(context.Background())
+}
+
+// GenerateContext is like Generate but stops as soon as ctx is done.
+// Returns ErrTimeout when Options.Timeout elapses first, or ctx.Err() when
+// ctx itself is cancelled or reaches its deadline.
+// With a Pattern, ErrPatternUnsatisfied is returned once PatternAttempts
+// candidate grids have been tried; otherwise ErrGenerationFailed is returned
+// once Attempts grids have been dug without success.
+func (g *Generator) GenerateContext(ctx context.Context) (puzzle *board.Board, solution *board.Board, err error) {
+	if g.options.Pattern == nil && (g.options.ClueCount < MinValidClueCount || g.options.ClueCount > MaxValidClueCount) {
 		return nil, nil, ErrInvalidClueCount
 	}
 
-	start := time.Now()
-	timeout := g.options.Timeout
+	if g.options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, g.options.Timeout, ErrTimeout)
+		defer cancel()
+	}
 
-	for {
-		if time.Since(start) >= timeout {
-			return nil, nil, ErrGenerationFailed
+	if g.options.Pattern != nil {
+		return g.generatePattern(ctx)
+	}
+
+	for attempt := 0; g.options.Attempts <= 0 || attempt < g.options.Attempts; attempt++ {
+		if ctx.Err() != nil {
+			return nil, nil, contextErr(ctx)
 		}
 
 		// Generate a complete valid board
-		solution, err = g.generateSolution()
+		solution, err = g.generateSolution(ctx)
 		if err != nil {
 			continue
 		}
 
 		// Remove clues to create the puzzle
-		puzzle, err = g.removeCells(solution)
+		puzzle, err = g.removeCells(ctx, solution)
 		if err != nil {
 			continue
 		}
 
 		// Verify uniqueness if required
 		if g.options.EnsureUnique {
-			if !g.hasUniqueSolution(puzzle) {
+			if unique, err := g.hasUniqueSolution(ctx, puzzle); err != nil || !unique {
+				continue
+			}
+		}
+
+		// Symmetric digging can leave single givens that are redundant.
+		if g.options.Minimal {
+			if minimal, err := isMinimal(ctx, puzzle); err != nil || !minimal {
 				continue
 			}
 		}
 
 		return puzzle, solution, nil
 	}
+	return nil, nil, ErrGenerationFailed
+}
+
+// contextErr returns the error to report for a done context: ErrTimeout when
+// the generator's own Timeout expired, otherwise ctx.Err().
+func contextErr(ctx context.Context) error {
+	if errors.Is(context.Cause(ctx), ErrTimeout) {
+		return ErrTimeout
+	}
+	return ctx.Err()
 }
 
 // generateSolution creates a complete valid Sudoku board.
-func (g *Generator) generateSolution() (*board.Board, error) {
+func (g *Generator) generateSolution(ctx context.Context) (*board.Board, error) {
 	// Pass the layout so the solver operates with the correct region structure.
 	b := board.New(g.options.Layout)
 
 	// Use solver with randomization to generate a complete board
+	// Dancing links avoids the heavy-tailed run times randomized backtracking
+	// shows on empty jigsaw boards.
+	// Seed the solver from the generator's stream so a seeded generator
+	// reproduces the same solution grid. Even so, some seeds wander for
+	// seconds on some jigsaw layouts where most finish in well under a
+	// millisecond, so the search is cut short and retried with a new seed.
 	s := solver.New(b, &solver.Options{
 		MaxSolutions: 1,
 		Randomize:    true,
-		Timeout:      g.options.Timeout,
+		Seed:         g.rng.Int63() | 1,
+		Engine:       solver.DancingLinks,
+		MaxNodes:     solutionMaxNodes,
 	})
 
-	return s.Solve()
+	return s.SolveContext(ctx)
 }
 
 // removeCells removes clues from a complete board to create a puzzle.
-func (g *Generator) removeCells(solution *board.Board) (*board.Board, error) {
+// Clues are removed one symmetry orbit at a time, never taking the puzzle
+// below ClueCount, and succeed once at most maxClues() remain. In Minimal
+// mode every orbit is tried and the target is ignored.
+func (g *Generator) removeCells(ctx context.Context, solution *board.Board) (*board.Board, error) {
 	puzzle := solution.Clone()
 
 	// Calculate how many cells to remove
 	targetClues := g.options.ClueCount
 	cellsToRemove := board.CellCount - targetClues
+	if g.options.Minimal {
+		cellsToRemove = board.CellCount
+	}
 
-	// Create shuffled list of all positions
-	positions := g.rng.Perm(board.CellCount)
+	// Create shuffled list of all orbits; without symmetry each is one cell.
+	orbits := g.options.Symmetry.Orbits()
+	order := g.rng.Perm(len(orbits))
 
-	// Remove cells until we reach target clues
+	// Remove orbits until we reach target clues
 	cellsRemoved := 0
-	for _, pos := range positions {
+	for _, i := range order {
 		if cellsRemoved >= cellsToRemove {
 			break
 		}
+		if ctx.Err() != nil {
+			return puzzle, contextErr(ctx)
+		}
 
-		// Try removing this cell
-		val := puzzle.Get(pos)
-		if val == board.EmptyCell {
+		// Skip orbits that would take the puzzle below the target.
+		orbit := orbits[i]
+		if cellsRemoved+len(orbit) > cellsToRemove {
 			continue
 		}
 
-		puzzle.Clear(pos)
-		cellsRemoved++
+		// Try removing this orbit
+		vals := make([]int, len(orbit))
+		for j, pos := range orbit {
+			vals[j] = puzzle.Get(pos)
+			puzzle.Clear(pos)
+		}
+		cellsRemoved += len(orbit)
 
 		// Verify the puzzle still has a unique solution
 		if g.options.EnsureUnique {
-			if !g.hasUniqueSolution(puzzle) {
-				// Restore the cells
-				puzzle.SetForce(pos, val)
-				cellsRemoved--
+			unique, err := g.hasUniqueSolution(ctx, puzzle)
+			if err != nil {
+				return puzzle, err
+			}
+			if !unique {
+				// Restore the whole orbit
+				for j, pos := range orbit {
+					puzzle.SetForce(pos, vals[j])
+				}
+				cellsRemoved -= len(orbit)
 			}
 		}
 	}
 
-	if cellsRemoved == cellsToRemove {
+	if g.options.Minimal || board.CellCount-cellsRemoved <= g.maxClues() {
 		return puzzle, nil
 	} else {
 		return puzzle, ErrDiggingFailed
 	}
 }
 
+// maxClues returns the most clues a dug puzzle may keep.
+func (g *Generator) maxClues() int {
+	if g.options.MaxClueCount > 0 {
+		return max(g.options.MaxClueCount, g.options.ClueCount)
+	}
+	return g.options.ClueCount + g.options.Symmetry.maxOrbit() - 1
+}
+
 // hasUniqueSolution checks if the puzzle has exactly one solution.
-func (g *Generator) hasUniqueSolution(puzzle *board.Board) bool {
-	s := solver.New(puzzle, &solver.Options{
-		MaxSolutions: 2,
-		Randomize:    false,
-		Timeout:      g.options.Timeout,
-	})
+// Returns an error only if ctx is done before the check completes.
+func (g *Generator) hasUniqueSolution(ctx context.Context, puzzle *board.Board) (bool, error) {
+	count, err := solver.CountSolutionsContext(ctx, puzzle, 2)
+	if err != nil && ctx.Err() != nil {
+		return false, err
+	}
+	return err == nil && count == 1, nil
+}
 
-	solutions := g.countSolutions(s)
-	return solutions == 1
+// IsMinimal reports whether a puzzle with a unique solution loses that
+// uniqueness when any single given is removed. Puzzles without a unique
+// solution are never minimal.
+func IsMinimal(puzzle *board.Board) (bool, error) {
+	return isMinimal(context.Background(), puzzle)
 }
 
-// countSolutions counts the number of solutions for a puzzle.
-func (g *Generator) countSolutions(s *solver.Solver) int {
-	count := 0
-
-	// Use backtracking to count solutions
-	var backtrack func(*board.Board) bool
-	backtrack = func(b *board.Board) bool {
-		// Apply constraint propagation
-		tempSolver := solver.New(b, &solver.Options{
-			MaxSolutions: 1,
-			Randomize:    false,
-		})
-
-		if err := tempSolver.PropagateConstraints(); err != nil {
-			return false
+// isMinimal is IsMinimal, stopping with ctx's error when ctx is done.
+func isMinimal(ctx context.Context, puzzle *board.Board) (bool, error) {
+	count, err := solver.CountSolutionsContext(ctx, puzzle, 2)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, contextErr(ctx)
 		}
+		return false, err
+	}
+	if count != 1 {
+		return false, nil
+	}
 
-		// Check if solved
-		if tempSolver.Board.EmptyCount() == 0 {
-			count++
-			return count < 2 // Stop after finding 2 solutions
+	p := puzzle.Clone()
+	for pos := range board.CellCount {
+		val := p.Get(pos)
+		if val == board.EmptyCell {
+			continue
 		}
-
-		// Find MRV cell
-		pos, candidates := tempSolver.FindMRVCell()
-		if len(candidates) == 0 {
-			return false
+		p.Clear(pos)
+		count, err := solver.CountSolutionsContext(ctx, p, 2)
+		p.SetForce(pos, val)
+		if err != nil {
+			return false, contextErr(ctx)
 		}
-
-		for _, val := range candidates {
-			if count >= 2 {
-				return false
-			}
-
-			clone := tempSolver.Board.Clone()
-			clone.SetForce(pos, val)
-
-			backtrack(clone)
+		if count == 1 {
+			return false, nil
 		}
-
-		return count < 2
 	}
-
-	backtrack(s.Board.Clone())
-	return count
+	return true, nil
 }
 
 // GenerateWithClueCount is a convenience function to generate a puzzle with a specific clue count.
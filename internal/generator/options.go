@@ -4,14 +4,38 @@ import (
 	"time"
 
 	"github.com/rybkr/sudoku/internal/board"
+	"github.com/rybkr/sudoku/internal/solver"
 )
 
 // Options configures puzzle generation behavior.
 type Options struct {
 	ClueCount    int           // Number of clues to add to the puzzle
-	Timeout      time.Duration // Timeout limits generation time
+	Timeout      time.Duration // Timeout limits generation time per Generate call (0 = none)
 	Seed         int64         // Seed for reproducible puzzles (0 = random)
 	EnsureUnique bool          // EnsureUnique verifies single solution
+	// Symmetry is kept by the clue pattern. Clues are removed an orbit at a
+	// time, so the target may be overshot by up to one orbit.
+	Symmetry Symmetry
+	// MaxClueCount is the most clues accepted when ClueCount cannot be hit
+	// exactly. 0 allows ClueCount plus one less than the largest orbit.
+	MaxClueCount int
+	// Minimal keeps digging past ClueCount until no clue can be removed, and
+	// discards puzzles in which a single given could still be removed
+	// without losing uniqueness. ClueCount and MaxClueCount are then only a
+	// guide; the result has as many clues as its minimal form needs.
+	Minimal bool
+	// Pattern, when set, fixes which cells hold the givens. Instead of
+	// digging, solution grids are searched until one restricted to the
+	// pattern has a unique solution; ClueCount, Symmetry and Minimal are
+	// ignored.
+	Pattern *Pattern
+	// PatternAttempts caps the candidate grids tried against Pattern
+	// (0 = DefaultPatternAttempts).
+	PatternAttempts int
+	// Attempts caps how many solution grids are dug before giving up with
+	// ErrGenerationFailed (0 = no limit). Unlike Timeout, where it stops
+	// does not depend on the speed of the machine.
+	Attempts int
 	// Layout specifies the board region structure. nil means StandardLayout.
 	Layout *board.Layout
 }
@@ -24,6 +48,59 @@ func DefaultOptions(clueCount int) *Options {
 		Timeout:      10 * time.Second,
 		Seed:         0,
 		EnsureUnique: true,
+		Symmetry:     SymmetryNone,
 		Layout:       nil, // nil → StandardLayout inside board.New
 	}
 }
+
+// categoryClues is the clue count range to draw from for each category, on
+// standard and jigsaw boards. Each range is where the category is most
+// common among generated puzzles. Of the puzzles drawn from its range, about
+// nine in ten are Easy, a quarter (two in five on jigsaw boards) Medium, one
+// in eight Hard, one in eighteen Expert, the rarest, and one in five (one in
+// two) Diabolical; TestCategoryCluesFrequency measures these. Jigsaw regions
+// constrain less than boxes, so jigsaw puzzles need fewer clues for the same
+// rating.
+var categoryClues = map[string][5][2]int{
+	"standard": {
+		solver.Easy:       {30, 36},
+		solver.Medium:     {24, 28},
+		solver.Hard:       {23, 28},
+		solver.Expert:     {24, 28},
+		solver.Diabolical: {23, 25},
+	},
+	"jigsaw": {
+		solver.Easy:       {28, 34},
+		solver.Medium:     {22, 26},
+		solver.Hard:       {20, 24},
+		solver.Expert:     {20, 24},
+		solver.Diabolical: {20, 22},
+	},
+}
+
+// CategoryClues returns the clue count range, inclusive, that best produces
+// puzzles of category on boardType ("standard", "jigsaw" or "" for
+// standard). Unknown categories get DefaultClueCount.
+func CategoryClues(category solver.Category, boardType string) (min, max int) {
+	if category < solver.Easy || category > solver.Diabolical {
+		return DefaultClueCount, DefaultClueCount
+	}
+	r := categoryClues[dailyType(boardType)][category]
+	return r[0], r[1]
+}
+
+// RangeClues returns the clue count range, inclusive, that covers
+// CategoryClues for every category the score range r spans on boardType.
+func RangeClues(r solver.ScoreRange, boardType string) (minClues, maxClues int) {
+	lo, hi := solver.CategoryOf(r.Min), solver.CategoryOf(r.Max)
+	// A category named as the upper bound excludes the next one's minimum.
+	if first, _ := hi.Range(); r.MaxExclusive && hi > lo && r.Max == first {
+		hi--
+	}
+	minClues, maxClues = CategoryClues(lo, boardType)
+	for c := lo + 1; c <= hi; c++ {
+		a, b := CategoryClues(c, boardType)
+		minClues, maxClues = min(minClues, a), max(maxClues, b)
+	}
+	return minClues, maxClues
+}
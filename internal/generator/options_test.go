@@ -0,0 +1,81 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/rybkr/sudoku/internal/board"
+	"github.com/rybkr/sudoku/internal/solver"
+)
+
+var samples = flag.Int("samples", 0, "puzzles to generate per category in TestCategoryCluesFrequency (0 skips it)")
+
+// TestCategoryCluesFrequency checks the frequencies the categoryClues
+// comment states, which the retry cap of 'sudoku gen' relies on. It
+// generates puzzles the way a difficulty run does, drawing the clue count
+// from CategoryClues and a jigsaw layout per puzzle, with the same timeout
+// per puzzle, so it is slow and runs only when asked:
+//
+//	go test ./internal/generator -run CategoryCluesFrequency -samples 1000 -timeout 1h -v
+//
+// A category passes when at least half its stated share of the puzzles
+// fall in it; puzzles that time out are left out of the count. The run is
+// seeded, so a failure reproduces.
+func TestCategoryCluesFrequency(t *testing.T) {
+	if *samples <= 0 {
+		t.Skip("pass -samples to sample each category")
+	}
+
+	// stated is each category's share as the comment gives it.
+	stated := map[string][5]float64{
+		"standard": {solver.Easy: 9. / 10, solver.Medium: 1. / 4, solver.Hard: 1. / 8, solver.Expert: 1. / 18, solver.Diabolical: 1. / 5},
+		"jigsaw":   {solver.Easy: 9. / 10, solver.Medium: 2. / 5, solver.Hard: 1. / 8, solver.Expert: 1. / 18, solver.Diabolical: 1. / 2},
+	}
+	for _, boardType := range []string{"standard", "jigsaw"} {
+		for c := solver.Easy; c <= solver.Diabolical; c++ {
+			t.Run(boardType+"/"+c.String(), func(t *testing.T) {
+				minClues, maxClues := CategoryClues(c, boardType)
+				pool := &Pool{
+					Seed:    1,
+					Timeout: 10 * time.Second,
+					Options: func(_ int, rng *rand.Rand) *Options {
+						opts := DefaultOptions(minClues + rng.Intn(maxClues-minClues+1))
+						opts.Timeout = 0
+						if boardType == "jigsaw" {
+							opts.Layout = board.RandomJigsawLayout(rng)
+						}
+						return opts
+					},
+				}
+				hits, rated := 0, 0
+				for res := range pool.Run(context.Background(), *samples) {
+					// Rating stops on the bare deadline, generating on ErrTimeout.
+					if errors.Is(res.Err, ErrTimeout) || errors.Is(res.Err, context.DeadlineExceeded) {
+						continue
+					}
+					if res.Err != nil {
+						t.Fatalf("puzzle %d: %v", res.Index+1, res.Err)
+					}
+					rated++
+					if res.Rating.Category == c {
+						hits++
+					}
+				}
+				if rated == 0 {
+					t.Fatal("every puzzle timed out")
+				}
+
+				share := float64(hits) / float64(rated)
+				t.Logf("%d of %d puzzles with %d-%d clues are %s (%.3f); %d timed out",
+					hits, rated, minClues, maxClues, c, share, *samples-rated)
+				if want := stated[boardType][c]; share < want/2 {
+					t.Errorf("%s share %.3f is under half the stated %.3f", c, share, want)
+				}
+			})
+		}
+	}
+}
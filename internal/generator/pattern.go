@@ -0,0 +1,195 @@
+package generator
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/rybkr/sudoku/internal/board"
+	"github.com/rybkr/sudoku/internal/solver"
+)
+
+// DefaultPatternAttempts is how many candidate grids are tried against a
+// pattern when Options.PatternAttempts is 0.
+const DefaultPatternAttempts = 2000
+
+var (
+	ErrInvalidPattern     = errors.New("invalid clue pattern")
+	ErrPatternUnsatisfied = errors.New("no solution grid gives the pattern a unique solution")
+)
+
+// Pattern marks the cells that must hold the givens of a puzzle.
+type Pattern [board.CellCount]bool
+
+// ParsePattern reads a pattern of nine rows of nine cells, where 'X' marks a
+// given and '.' an empty cell. Spaces within a row, blank lines and lines
+// starting with '#' are ignored. Errors wrap ErrInvalidPattern and name the
+// offending line.
+func ParsePattern(r io.Reader) (*Pattern, error) {
+	var p Pattern
+	rows := 0
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if rows == 9 {
+			return nil, fmt.Errorf("%w: line %d: more than 9 rows", ErrInvalidPattern, lineNum)
+		}
+
+		cells := strings.ReplaceAll(line, " ", "")
+		if len(cells) != 9 {
+			return nil, fmt.Errorf("%w: line %d: row has %d cells, want 9", ErrInvalidPattern, lineNum, len(cells))
+		}
+		for col, ch := range cells {
+			switch ch {
+			case 'X', 'x':
+				p[board.MakePos(rows, col)] = true
+			case '.':
+			default:
+				return nil, fmt.Errorf("%w: line %d: unexpected %q (use 'X' for a given, '.' for empty)", ErrInvalidPattern, lineNum, ch)
+			}
+		}
+		rows++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if rows != 9 {
+		return nil, fmt.Errorf("%w: found %d rows, want 9", ErrInvalidPattern, rows)
+	}
+
+	if n := p.ClueCount(); n < MinValidClueCount {
+		return nil, fmt.Errorf("%w: %d givens, a unique puzzle needs at least %d", ErrInvalidPattern, n, MinValidClueCount)
+	}
+	return &p, nil
+}
+
+// ClueCount returns the number of givens the pattern marks.
+func (p *Pattern) ClueCount() int {
+	n := 0
+	for _, given := range p {
+		if given {
+			n++
+		}
+	}
+	return n
+}
+
+// String returns the pattern as nine lines of 'X' and '.'.
+func (p *Pattern) String() string {
+	var sb strings.Builder
+	for pos, given := range p {
+		if given {
+			sb.WriteByte('X')
+		} else {
+			sb.WriteByte('.')
+		}
+		if pos%9 == 8 && pos < board.CellCount-1 {
+			sb.WriteByte('\n')
+		}
+	}
+	return sb.String()
+}
+
+// patternScoreLimit caps the solutions counted when scoring a candidate
+// grid against a pattern; the count only has to rank near misses.
+const patternScoreLimit = 64
+
+// patternRestart is how many steps without improvement the search takes
+// before starting over from a fresh solution grid.
+const patternRestart = 200
+
+// generatePattern searches for a solution grid whose restriction to the
+// pattern has a unique solution. Random grids almost never qualify, so the
+// search hill-climbs instead: each step re-solves the puzzle with one or two
+// givens freed, and keeps the new grid unless its restriction has more
+// solutions than the current one. Each step counts as one of PatternAttempts.
+func (g *Generator) generatePattern(ctx context.Context) (puzzle *board.Board, solution *board.Board, err error) {
+	attempts := g.options.PatternAttempts
+	if attempts <= 0 {
+		attempts = DefaultPatternAttempts
+	}
+
+	var givens []int
+	for pos, given := range g.options.Pattern {
+		if given {
+			givens = append(givens, pos)
+		}
+	}
+
+	score, stale := 0, 0
+	for range attempts {
+		if ctx.Err() != nil {
+			return nil, nil, contextErr(ctx)
+		}
+
+		var candidate *board.Board
+		if solution == nil || stale >= patternRestart {
+			candidate, err = g.generateSolution(ctx)
+			solution, score, stale = nil, 0, 0
+		} else {
+			candidate, err = g.perturbPattern(ctx, solution, givens)
+		}
+		if err != nil {
+			continue
+		}
+
+		puzzle = g.restrictToPattern(candidate)
+		count, err := solver.CountSolutionsContext(ctx, puzzle, patternScoreLimit)
+		if err != nil {
+			continue
+		}
+		if count == 1 || !g.options.EnsureUnique {
+			return puzzle, candidate, nil
+		}
+
+		if solution == nil || count <= score {
+			if count < score {
+				stale = 0
+			}
+			solution, score = candidate, count
+		}
+		stale++
+	}
+
+	if ctx.Err() != nil {
+		return nil, nil, contextErr(ctx)
+	}
+	return nil, nil, ErrPatternUnsatisfied
+}
+
+// restrictToPattern returns the solution with every cell outside the
+// pattern cleared.
+func (g *Generator) restrictToPattern(solution *board.Board) *board.Board {
+	puzzle := solution.Clone()
+	for pos, given := range g.options.Pattern {
+		if !given {
+			puzzle.Clear(pos)
+		}
+	}
+	return puzzle
+}
+
+// perturbPattern returns a random solution grid that agrees with solution on
+// all but one or two of the pattern's givens.
+func (g *Generator) perturbPattern(ctx context.Context, solution *board.Board, givens []int) (*board.Board, error) {
+	puzzle := g.restrictToPattern(solution)
+	for range 1 + g.rng.Intn(2) {
+		puzzle.Clear(givens[g.rng.Intn(len(givens))])
+	}
+
+	s := solver.New(puzzle, &solver.Options{
+		MaxSolutions: 1,
+		Randomize:    true,
+		Seed:         g.rng.Int63() | 1,
+		Engine:       solver.DancingLinks,
+	})
+	return s.SolveContext(ctx)
+}
@@ -0,0 +1,153 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/rybkr/sudoku/internal/board"
+	"github.com/rybkr/sudoku/internal/solver"
+)
+
+// Pool generates puzzles concurrently with a fixed number of workers.
+//
+// Puzzle i is always generated from its own random stream, seeded by
+// DeriveSeed(Seed, i), so for a given Seed the results are identical no
+// matter how many workers run or in which order they finish.
+type Pool struct {
+	// Workers is the number of concurrent generators (<= 0 means GOMAXPROCS).
+	Workers int
+	// Seed is the base seed all puzzle streams derive from (0 = time-based).
+	Seed int64
+	// Timeout bounds the wall-clock time spent on each puzzle, including
+	// difficulty retries (0 = none).
+	Timeout time.Duration
+	// Options builds the generator options for puzzle index, drawing any
+	// random choices (clue count, jigsaw layout) from the puzzle's stream.
+	// nil means DefaultOptions(DefaultClueCount).
+	Options func(index int, rng *rand.Rand) *Options
+	// Accept reports whether a puzzle's rating is acceptable (nil accepts all).
+	Accept func(solver.Rating) bool
+	// MaxRetries caps how many rejected candidates are discarded per puzzle.
+	MaxRetries int
+}
+
+// Result is the outcome of generating one puzzle in a Pool.
+type Result struct {
+	Index     int   // Index is the puzzle's position in the run, from 0
+	Seed      int64 // Seed is the puzzle's stream seed
+	Puzzle    *board.Board
+	Solution  *board.Board
+	Rating    solver.Rating
+	Discarded int   // Discarded counts candidates rejected by Accept
+	Err       error // Err is non-nil if the puzzle could not be generated
+}
+
+// DeriveSeed returns the seed of stream index under base, using the
+// SplitMix64 finalizer so that neighbouring indices get unrelated streams.
+func DeriveSeed(base int64, index int) int64 {
+	z := uint64(base) + uint64(index+1)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	z ^= z >> 31
+	// Seed 0 means "random" to Generator, so never hand it out.
+	if z == 0 {
+		z = 1
+	}
+	return int64(z)
+}
+
+// Run generates n puzzles and returns a channel that receives one Result per
+// puzzle, in completion order, and is closed once all workers have finished.
+// Cancelling ctx stops the workers: puzzles in progress report ctx's error
+// and puzzles not yet started produce no Result.
+func (p *Pool) Run(ctx context.Context, n int) <-chan Result {
+	workers := p.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, n)
+
+	base := p.Seed
+	if base == 0 {
+		base = time.Now().UnixNano()
+	}
+
+	jobs := make(chan int)
+	results := make(chan Result, n)
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results <- p.generate(ctx, i, DeriveSeed(base, i))
+			}
+		}()
+	}
+
+	go func() {
+		defer close(results)
+		defer wg.Wait()
+		defer close(jobs)
+		for i := range n {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return results
+}
+
+// generate produces puzzle index from its seeded stream, retrying while the
+// rating is rejected by Accept.
+func (p *Pool) generate(ctx context.Context, index int, seed int64) Result {
+	res := Result{Index: index, Seed: seed}
+
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, p.Timeout, ErrTimeout)
+		defer cancel()
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	var opts *Options
+	if p.Options != nil {
+		opts = p.Options(index, rng)
+	} else {
+		opts = DefaultOptions(DefaultClueCount)
+	}
+	o := *opts
+	o.Seed = rng.Int63() | 1
+	gen := New(&o)
+
+	for {
+		puzzle, solution, err := gen.GenerateContext(ctx)
+		if err != nil {
+			res.Err = err
+			return res
+		}
+		rating, err := solver.RateContext(ctx, puzzle)
+		if err != nil {
+			res.Err = fmt.Errorf("rating failed: %w", err)
+			return res
+		}
+		if p.Accept == nil || p.Accept(rating) {
+			res.Puzzle, res.Solution, res.Rating = puzzle, solution, rating
+			return res
+		}
+
+		res.Discarded++
+		if res.Discarded > p.MaxRetries {
+			res.Err = ErrDifficultyOutOfRange
+			return res
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/rybkr/sudoku/internal/board"
+)
+
+// Symmetry is a symmetry the clue pattern of a generated puzzle keeps.
+// Cells that map onto each other under the symmetry form an orbit; clues are
+// removed and restored an orbit at a time so the pattern stays symmetric.
+type Symmetry int
+
+const (
+	SymmetryNone       Symmetry = iota // No symmetry; cells are dug one at a time
+	Rotational180                      // Unchanged by a half turn about the centre
+	Rotational90                       // Unchanged by a quarter turn about the centre
+	MirrorHorizontal                   // Mirrored top to bottom about the middle row
+	MirrorVertical                     // Mirrored left to right about the middle column
+	MirrorDiagonal                     // Mirrored about the main (top-left to bottom-right) diagonal
+	MirrorAntiDiagonal                 // Mirrored about the anti-diagonal (top-right to bottom-left)
+	Dihedral                           // All of the above at once
+)
+
+// symmetryNames are the names accepted by ParseSymmetry, indexed by Symmetry.
+var symmetryNames = [...]string{
+	SymmetryNone:       "none",
+	Rotational180:      "rotational",
+	Rotational90:       "rotational90",
+	MirrorHorizontal:   "horizontal",
+	MirrorVertical:     "vertical",
+	MirrorDiagonal:     "diagonal",
+	MirrorAntiDiagonal: "anti-diagonal",
+	Dihedral:           "dihedral",
+}
+
+// symmetryAliases are additional spellings accepted by ParseSymmetry.
+var symmetryAliases = map[string]Symmetry{
+	"":             SymmetryNone,
+	"180":          Rotational180,
+	"rot180":       Rotational180,
+	"90":           Rotational90,
+	"rot90":        Rotational90,
+	"antidiagonal": MirrorAntiDiagonal,
+	"full":         Dihedral,
+	"all":          Dihedral,
+}
+
+func (s Symmetry) String() string {
+	if s >= 0 && int(s) < len(symmetryNames) {
+		return symmetryNames[s]
+	}
+	return fmt.Sprintf("Symmetry(%d)", int(s))
+}
+
+// ParseSymmetry parses a symmetry name such as "rotational" or "diagonal".
+func ParseSymmetry(s string) (Symmetry, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	for sym, n := range symmetryNames {
+		if name == n {
+			return Symmetry(sym), nil
+		}
+	}
+	if sym, ok := symmetryAliases[name]; ok {
+		return sym, nil
+	}
+	return SymmetryNone, fmt.Errorf("unknown symmetry %q: must be one of %s", s, strings.Join(symmetryNames[:], ", "))
+}
+
+// transform maps a (row, col) cell to its image under one symmetry operation.
+type transform func(row, col int) (int, int)
+
+var (
+	rotate90       transform = func(r, c int) (int, int) { return c, 8 - r }
+	rotate180      transform = func(r, c int) (int, int) { return 8 - r, 8 - c }
+	rotate270      transform = func(r, c int) (int, int) { return 8 - c, r }
+	flipHorizontal transform = func(r, c int) (int, int) { return 8 - r, c }
+	flipVertical   transform = func(r, c int) (int, int) { return r, 8 - c }
+	flipDiagonal   transform = func(r, c int) (int, int) { return c, r }
+	flipAnti       transform = func(r, c int) (int, int) { return 8 - c, 8 - r }
+)
+
+// transforms returns the non-identity operations of the symmetry's group.
+func (s Symmetry) transforms() []transform {
+	switch s {
+	case Rotational180:
+		return []transform{rotate180}
+	case Rotational90:
+		return []transform{rotate90, rotate180, rotate270}
+	case MirrorHorizontal:
+		return []transform{flipHorizontal}
+	case MirrorVertical:
+		return []transform{flipVertical}
+	case MirrorDiagonal:
+		return []transform{flipDiagonal}
+	case MirrorAntiDiagonal:
+		return []transform{flipAnti}
+	case Dihedral:
+		return []transform{rotate90, rotate180, rotate270, flipHorizontal, flipVertical, flipDiagonal, flipAnti}
+	}
+	return nil
+}
+
+// Orbits partitions the board's cells into orbits under the symmetry.
+// Orbits are ordered by their smallest position and each lists its cells in
+// ascending order; with SymmetryNone every cell is an orbit of its own.
+func (s Symmetry) Orbits() [][]int {
+	ops := s.transforms()
+	var seen [board.CellCount]bool
+	var orbits [][]int
+	for pos := range board.CellCount {
+		if seen[pos] {
+			continue
+		}
+		seen[pos] = true
+		orbit := []int{pos}
+		row, col := pos/9, pos%9
+		for _, op := range ops {
+			if p := board.MakePos(op(row, col)); !seen[p] {
+				seen[p] = true
+				orbit = append(orbit, p)
+			}
+		}
+		slices.Sort(orbit)
+		orbits = append(orbits, orbit)
+	}
+	return orbits
+}
+
+// maxOrbit returns the size of the symmetry's largest orbit.
+func (s Symmetry) maxOrbit() int {
+	return len(s.transforms()) + 1
+}
@@ -0,0 +1,81 @@
+package output
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+func init() {
+	Register(Format{
+		Name:        "csv",
+		Description: "One row per puzzle with a header row",
+		Extensions:  []string{".csv"},
+		Solutions:   SolutionsInline,
+		New: func(w io.Writer, opts Options) Writer {
+			return &csvWriter{w: csv.NewWriter(w), opts: opts}
+		},
+	})
+}
+
+// csvWriter writes a row per puzzle. The columns follow the JSON records;
+// the solution column is left out with SolutionsNone, and SolutionsOnly
+// writes just the number and solution. Daily puzzles add a date column after
+// the number.
+type csvWriter struct {
+	w       *csv.Writer
+	opts    Options
+	started bool
+	dated   bool
+}
+
+func (c *csvWriter) header() []string {
+	head := []string{"number"}
+	if c.dated {
+		head = append(head, "date")
+	}
+	switch c.opts.Solutions {
+	case SolutionsOnly:
+		return append(head, "solution")
+	case SolutionsNone:
+		return append(head, "puzzle", "clues", "score", "category", "seed", "type", "layout")
+	}
+	return append(head, "puzzle", "solution", "clues", "score", "category", "seed", "type", "layout")
+}
+
+func (c *csvWriter) Write(p Puzzle) error {
+	if !c.started {
+		c.started = true
+		c.dated = p.Date != ""
+		if err := c.w.Write(c.header()); err != nil {
+			return err
+		}
+	}
+
+	rec := recordOf(p, c.opts)
+	row := []string{strconv.Itoa(rec.Number)}
+	if c.dated {
+		row = append(row, rec.Date)
+	}
+	if c.opts.Solutions == SolutionsOnly {
+		return c.w.Write(append(row, rec.Solution))
+	}
+	row = append(row, rec.Puzzle)
+	if c.opts.Solutions != SolutionsNone {
+		row = append(row, rec.Solution)
+	}
+	row = append(row,
+		strconv.Itoa(rec.Clues),
+		strconv.FormatFloat(rec.Difficulty.Score, 'f', 1, 64),
+		rec.Difficulty.Category,
+		strconv.FormatInt(rec.Seed, 10),
+		rec.Type,
+		rec.Layout,
+	)
+	return c.w.Write(row)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
@@ -0,0 +1,320 @@
+package output
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/rybkr/sudoku/internal/board"
+	"github.com/rybkr/sudoku/internal/theme"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+func init() {
+	Register(Format{
+		Name:        "html",
+		Description: "A printable HTML booklet, easiest first",
+		Extensions:  []string{".html", ".htm"},
+		Solutions:   SolutionsNone,
+		Themed:      true,
+		New: func(w io.Writer, opts Options) Writer {
+			return &htmlWriter{w: w, opts: opts}
+		},
+	})
+}
+
+// fallbackTheme styles booklets written without a theme.
+var fallbackTheme = &theme.Theme{
+	TitlePrefix: "Sudoku Puzzle",
+	BodyFont:    "Arial, sans-serif",
+	HeadingFont: "Arial, sans-serif",
+}
+
+// PuzzlePage holds pre-rendered data for a single puzzle page in the HTML template.
+type PuzzlePage struct {
+	Title        string
+	PuzzleNumber int
+	Difficulty   string
+	GridHTML     template.HTML
+	// SolutionHTML is the solved grid, whether or not the booklet shows
+	// solutions, for templates that place it themselves.
+	SolutionHTML template.HTML
+	Clues        int
+	Seed         int64  // Seed is the puzzle's own seed, derived from the run's
+	BoardType    string // BoardType is "standard" or "jigsaw"
+	// Date is the day of a daily puzzle, e.g. "Friday, 16 October 2026",
+	// and Anchor the id of its page for the calendar to link to; both are
+	// "" for other puzzles.
+	Date   string
+	Anchor string
+}
+
+// CalendarDay is one square of the calendar of daily puzzles; Day is 0 for
+// the padding before the first day and after the last.
+type CalendarDay struct {
+	Day    int
+	Anchor string
+}
+
+// TemplateData holds data for HTML template rendering.
+type TemplateData struct {
+	TitlePrefix string
+	BodyFont    string
+	HeadingFont string
+	ThemeClass  string
+	// ExtraCSS, Header and Footer come from the theme: CSS added to the
+	// style sheet and HTML for the top and bottom of every page.
+	ExtraCSS template.CSS
+	Header   template.HTML
+	Footer   template.HTML
+	Seed     int64
+	// Generated is when the booklet was written.
+	Generated   time.Time
+	PuzzlePages []PuzzlePage
+	// Sheets are the printed pages of puzzles: PuzzlePages, PerPage to a
+	// sheet. PageSize names the paper for CSS, e.g. "a4".
+	Sheets   [][]PuzzlePage
+	PerPage  int
+	PageSize string
+	// AnswerPages is the answer key: solution grids, answersPerPage to a
+	// page, each keyed to its PuzzleNumber.
+	AnswerPages [][]PuzzlePage
+	// Calendar lays out a run of daily puzzles as weeks, Monday first,
+	// linking each day to its page; CalendarTitle names the month. Both are
+	// empty unless there are several daily puzzles.
+	Calendar      [][]CalendarDay
+	CalendarTitle string
+}
+
+// answersPerPage is how many small solution grids share an answer-key page.
+const answersPerPage = 6
+
+// htmlWriter collects the puzzles and renders the booklet on Close, in
+// bookOrder.
+type htmlWriter struct {
+	w       io.Writer
+	opts    Options
+	puzzles []Puzzle
+}
+
+func (h *htmlWriter) Write(p Puzzle) error {
+	h.puzzles = append(h.puzzles, p)
+	return nil
+}
+
+func (h *htmlWriter) Close() error {
+	puzzles := bookOrder(h.puzzles)
+
+	// The seed drives the choice of page titles and is printed on each page.
+	rng := rand.New(rand.NewSource(h.opts.Seed))
+
+	t := h.opts.Theme
+	if t == nil {
+		t = fallbackTheme
+	}
+
+	// Pre-render each puzzle board into HTML so the template stays logic-free.
+	// Solution pages carry the number of the puzzle they solve. Inline
+	// solutions get a page each; otherwise they go to the answer key.
+	var pages, answers []PuzzlePage
+	for i, p := range puzzles {
+		title := t.TitlePrefix
+		if len(t.Titles) > 0 {
+			title = t.Titles[rng.Intn(len(t.Titles))]
+		}
+		page := PuzzlePage{
+			Title:        title,
+			PuzzleNumber: i + 1,
+			Difficulty:   p.Rating.String(),
+			GridHTML:     BoardHTML(p.Puzzle),
+			SolutionHTML: BoardHTML(p.Solution),
+			Clues:        p.Puzzle.ClueCount(),
+			Seed:         p.Seed,
+			BoardType:    p.Puzzle.Layout().Type,
+		}
+		if date, err := time.Parse(time.DateOnly, p.Date); err == nil {
+			page.Date = date.Format("Monday, 2 January 2006")
+			page.Anchor = "day-" + p.Date
+		}
+		solution := page
+		solution.Title = "Solution"
+		solution.GridHTML = page.SolutionHTML
+		solution.Anchor = ""
+
+		switch h.opts.Solutions {
+		case SolutionsInline:
+			pages = append(pages, page, solution)
+		case SolutionsSection:
+			pages = append(pages, page)
+			answers = append(answers, solution)
+		case SolutionsOnly:
+			answers = append(answers, solution)
+		default:
+			pages = append(pages, page)
+		}
+	}
+
+	perPage := h.opts.PerPage
+	if !ValidPerPage(perPage) {
+		perPage = 1
+	}
+	pageSize := h.opts.PageSize.Name
+	if pageSize == "" {
+		pageSize = A4.Name
+	}
+
+	data := TemplateData{
+		TitlePrefix: t.TitlePrefix,
+		BodyFont:    t.BodyFont,
+		HeadingFont: t.HeadingFont,
+		ThemeClass:  t.Class,
+		ExtraCSS:    template.CSS(t.CSS),
+		Header:      template.HTML(t.Header),
+		Footer:      template.HTML(t.Footer),
+		Seed:        h.opts.Seed,
+		Generated:   time.Now(),
+		PuzzlePages: pages,
+		Sheets:      chunk(pages, perPage),
+		PerPage:     perPage,
+		PageSize:    pageSize,
+		AnswerPages: chunk(answers, answersPerPage),
+	}
+	if len(puzzles) > 1 && h.opts.Solutions != SolutionsOnly {
+		data.Calendar, data.CalendarTitle = calendar(puzzles)
+	}
+
+	// A user's template replaces the built-in one.
+	if h.opts.Template != nil {
+		return h.opts.Template.Execute(h.w, data)
+	}
+	tmpl, err := template.ParseFS(templateFS, "templates/puzzles.html")
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+	if err := tmpl.Execute(h.w, data); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return nil
+}
+
+// chunk splits pages into runs of n.
+func chunk(pages []PuzzlePage, n int) [][]PuzzlePage {
+	var runs [][]PuzzlePage
+	for len(pages) > 0 {
+		k := min(n, len(pages))
+		runs = append(runs, pages[:k])
+		pages = pages[k:]
+	}
+	return runs
+}
+
+// calendar lays out the days from the first daily puzzle to the last as rows
+// of seven, Monday first, padded with empty days, and names the months they
+// span. It returns nil if the puzzles are not dated.
+func calendar(puzzles []Puzzle) ([][]CalendarDay, string) {
+	first, err := time.Parse(time.DateOnly, puzzles[0].Date)
+	if err != nil {
+		return nil, ""
+	}
+	last, err := time.Parse(time.DateOnly, puzzles[len(puzzles)-1].Date)
+	if err != nil {
+		return nil, ""
+	}
+	dated := make(map[string]bool, len(puzzles))
+	for _, p := range puzzles {
+		dated[p.Date] = true
+	}
+
+	// time.Weekday counts from Sunday; shift so Monday is column 0.
+	days := make([]CalendarDay, (int(first.Weekday())+6)%7)
+	for d := first; !d.After(last); d = d.AddDate(0, 0, 1) {
+		day := CalendarDay{Day: d.Day()}
+		if key := d.Format(time.DateOnly); dated[key] {
+			day.Anchor = "day-" + key
+		}
+		days = append(days, day)
+	}
+	for len(days)%7 != 0 {
+		days = append(days, CalendarDay{})
+	}
+
+	weeks := make([][]CalendarDay, 0, len(days)/7)
+	for i := 0; i < len(days); i += 7 {
+		weeks = append(weeks, days[i:i+7])
+	}
+
+	title := first.Format("January 2006")
+	if last.Year() != first.Year() || last.Month() != first.Month() {
+		title += " to " + last.Format("January 2006")
+	}
+	return weeks, title
+}
+
+// BoardHTML converts a board to a safe HTML table for embedding in a template.
+// For jigsaw layouts, each cell receives directional border classes (border-top,
+// border-right, border-bottom, border-left) wherever the cell abuts a different
+// region — these produce bold printed region boundaries.
+// For standard layouts the existing nth-child CSS handles thick 3×3-box borders.
+func BoardHTML(b *board.Board) template.HTML {
+	layout := b.Layout()
+	isJigsaw := layout.Type == "jigsaw"
+
+	var sb strings.Builder
+	gridClass := "sudoku-grid"
+	if isJigsaw {
+		gridClass += " jigsaw-grid"
+	}
+	fmt.Fprintf(&sb, `<div class="%s"><table>`, gridClass)
+	for row := range 9 {
+		sb.WriteString("<tr>")
+		for col := range 9 {
+			pos := board.MakePos(row, col)
+			val := b.Get(pos)
+
+			// Build CSS class list for this cell.
+			var classes []string
+			if val == board.EmptyCell {
+				classes = append(classes, "empty")
+			}
+			if isJigsaw {
+				// Add a directional border class for each edge where the
+				// adjacent cell belongs to a different region (or is outside
+				// the grid, which also marks a boundary).
+				borders := layout.Borders(pos)
+				if borders&board.BorderTop != 0 {
+					classes = append(classes, "border-top")
+				}
+				if borders&board.BorderBottom != 0 {
+					classes = append(classes, "border-bottom")
+				}
+				if borders&board.BorderLeft != 0 {
+					classes = append(classes, "border-left")
+				}
+				if borders&board.BorderRight != 0 {
+					classes = append(classes, "border-right")
+				}
+			}
+
+			classAttr := ""
+			if len(classes) > 0 {
+				classAttr = ` class="` + strings.Join(classes, " ") + `"`
+			}
+
+			if val == board.EmptyCell {
+				fmt.Fprintf(&sb, "<td%s></td>", classAttr)
+			} else {
+				fmt.Fprintf(&sb, "<td%s>%d</td>", classAttr, val)
+			}
+		}
+		sb.WriteString("</tr>")
+	}
+	sb.WriteString("</table></div>")
+	return template.HTML(sb.String())
+}
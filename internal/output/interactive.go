@@ -0,0 +1,124 @@
+package output
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+
+	"github.com/rybkr/sudoku/internal/board"
+)
+
+func init() {
+	Register(Format{
+		Name:        "html-interactive",
+		Description: "A self-contained HTML page to play the puzzles in a browser",
+		Solutions:   SolutionsNone,
+		Themed:      true,
+		New: func(w io.Writer, opts Options) Writer {
+			return &interactiveWriter{w: w, opts: opts}
+		},
+	})
+}
+
+// InteractivePuzzle is one puzzle of the interactive page. The grid is
+// rendered by BoardHTML, so jigsaw regions carry the same border classes
+// as in the printed booklet; the page's script makes its cells editable.
+type InteractivePuzzle struct {
+	Number     int
+	Title      string
+	Difficulty string
+	GridHTML   template.HTML
+	// Layout is the region map, as from board.Layout.String, for the
+	// script to find conflicts.
+	Layout string
+	// Key is the solution, obfuscated with Salt by obfuscate so that it
+	// cannot be read off the page source at a glance.
+	Key  string
+	Salt uint32
+}
+
+// InteractiveData holds data for the interactive page template.
+type InteractiveData struct {
+	TitlePrefix string
+	BodyFont    string
+	HeadingFont string
+	ThemeClass  string
+	ExtraCSS    template.CSS
+	Seed        int64
+	Puzzles     []InteractivePuzzle
+	// Reveal offers a button that fills in the solution.
+	Reveal bool
+}
+
+// interactiveWriter collects the puzzles and writes the page on Close,
+// sorted by difficulty like the booklet.
+type interactiveWriter struct {
+	w       io.Writer
+	opts    Options
+	puzzles []Puzzle
+}
+
+func (i *interactiveWriter) Write(p Puzzle) error {
+	i.puzzles = append(i.puzzles, p)
+	return nil
+}
+
+func (i *interactiveWriter) Close() error {
+	puzzles := bookOrder(i.puzzles)
+
+	t := i.opts.Theme
+	if t == nil {
+		t = fallbackTheme
+	}
+
+	data := InteractiveData{
+		TitlePrefix: t.TitlePrefix,
+		BodyFont:    t.BodyFont,
+		HeadingFont: t.HeadingFont,
+		ThemeClass:  t.Class,
+		ExtraCSS:    template.CSS(t.CSS),
+		Seed:        i.opts.Seed,
+		Reveal:      i.opts.Solutions != SolutionsNone,
+	}
+	for n, p := range puzzles {
+		// The solutions file starts every puzzle solved.
+		grid, title := p.Puzzle, t.TitlePrefix
+		if i.opts.Solutions == SolutionsOnly {
+			grid, title = p.Solution, "Solution"
+		}
+		salt := uint32(p.Seed) ^ uint32(p.Seed>>32)
+		data.Puzzles = append(data.Puzzles, InteractivePuzzle{
+			Number:     n + 1,
+			Title:      title,
+			Difficulty: p.Rating.String(),
+			GridHTML:   BoardHTML(grid),
+			Layout:     p.Puzzle.Layout().String(),
+			Key:        obfuscate(p.Solution, salt),
+			Salt:       salt,
+		})
+	}
+
+	tmpl, err := template.ParseFS(templateFS, "templates/interactive.html")
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+	if err := tmpl.Execute(i.w, data); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+	return nil
+}
+
+// obfuscate encodes the digits of b as letters 'a' to 'i', each shifted by
+// a pseudo-random offset from a generator seeded with salt. The page's
+// script runs the same generator to decode it; this hides the solution
+// from casual reading, not from a determined player.
+func obfuscate(b *board.Board, salt uint32) string {
+	key := make([]byte, board.CellCount)
+	x := salt
+	for pos := range board.CellCount {
+		x = (x*1103515245 + 12345) & 0x7fffffff
+		offset := int(x>>16) % 9
+		key[pos] = 'a' + byte((b.Get(pos)-1+offset)%9)
+	}
+	return string(key)
+}
@@ -0,0 +1,68 @@
+package output
+
+import (
+	"io"
+
+	"github.com/rybkr/sudoku/internal/record"
+)
+
+func init() {
+	Register(Format{
+		Name:        "json",
+		Description: "A JSON array of puzzle records",
+		Extensions:  []string{".json"},
+		Solutions:   SolutionsInline,
+		New: func(w io.Writer, opts Options) Writer {
+			return &jsonWriter{w: w, opts: opts}
+		},
+	})
+	Register(Format{
+		Name:        "jsonl",
+		Description: "Puzzle records as JSON Lines, one per line",
+		Extensions:  []string{".jsonl", ".ndjson"},
+		Solutions:   SolutionsInline,
+		New: func(w io.Writer, opts Options) Writer {
+			return &jsonWriter{w: w, opts: opts, lines: true}
+		},
+	})
+}
+
+// jsonWriter writes puzzle records: as an array once all have arrived, or
+// one line per puzzle as they arrive.
+type jsonWriter struct {
+	w       io.Writer
+	opts    Options
+	lines   bool
+	records []record.Record
+}
+
+// recordOf describes a puzzle for JSON and CSV output. Every placement other
+// than SolutionsNone keeps the solution, since a record holds a single puzzle.
+// The record carries the run's seed, which with its number regenerates it.
+func recordOf(p Puzzle, opts Options) record.Record {
+	solution := p.Solution
+	if opts.Solutions == SolutionsNone {
+		solution = nil
+	}
+	rec := record.New(p.Puzzle, solution, p.Rating)
+	rec.Number = p.Number
+	rec.Seed = opts.Seed
+	rec.Date = p.Date
+	return rec
+}
+
+func (j *jsonWriter) Write(p Puzzle) error {
+	rec := recordOf(p, j.opts)
+	if j.lines {
+		return record.WriteJSONL(j.w, rec)
+	}
+	j.records = append(j.records, rec)
+	return nil
+}
+
+func (j *jsonWriter) Close() error {
+	if j.lines {
+		return nil
+	}
+	return record.WriteJSON(j.w, j.records)
+}
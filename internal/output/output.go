@@ -0,0 +1,420 @@
+// Package output writes generated puzzles in the formats the CLI supports.
+//
+// Each format registers itself with Register and is then selected by name or
+// by the extension of the output file. A Writer receives the puzzles of a run
+// one at a time, in order, and finishes the output in Close, so streaming
+// formats can write as puzzles arrive while others collect them first.
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rybkr/sudoku/internal/board"
+	"github.com/rybkr/sudoku/internal/solver"
+	"github.com/rybkr/sudoku/internal/theme"
+)
+
+// Puzzle is one generated puzzle handed to a Writer.
+type Puzzle struct {
+	Number   int    // Number is the puzzle's position in the run, from 1
+	Seed     int64  // Seed is the puzzle's own seed, derived from the run's
+	Date     string // Date is the day of a daily puzzle as YYYY-MM-DD, or ""
+	Puzzle   *board.Board
+	Solution *board.Board
+	Rating   solver.Rating
+}
+
+// label names p in headings, e.g. "Puzzle #3", or "Puzzle for 2026-10-16"
+// when it is a daily puzzle. Booklets that renumber their puzzles pass the
+// number to show.
+func (p Puzzle) label(noun string, number int) string {
+	if p.Date != "" {
+		return noun + " for " + p.Date
+	}
+	return fmt.Sprintf("%s #%d", noun, number)
+}
+
+// bookOrder returns puzzles in the order booklets print them: easiest first,
+// or as given for daily puzzles, which arrive in date order.
+func bookOrder(puzzles []Puzzle) []Puzzle {
+	puzzles = append([]Puzzle(nil), puzzles...)
+	if len(puzzles) > 0 && puzzles[0].Date != "" {
+		return puzzles
+	}
+	sort.SliceStable(puzzles, func(i, j int) bool {
+		return puzzles[i].Rating.Score < puzzles[j].Rating.Score
+	})
+	return puzzles
+}
+
+// Solutions selects where a Writer puts the solutions.
+type Solutions int
+
+const (
+	// SolutionsDefault leaves the choice to the format.
+	SolutionsDefault Solutions = iota
+	// SolutionsNone omits solutions.
+	SolutionsNone
+	// SolutionsInline puts each solution right after its puzzle.
+	SolutionsInline
+	// SolutionsSection collects the solutions after all the puzzles.
+	SolutionsSection
+	// SolutionsFile writes the solutions to a separate file next to the
+	// output; see SolutionsPath.
+	SolutionsFile
+	// SolutionsOnly writes nothing but the solutions. Open uses it for the
+	// separate file of SolutionsFile.
+	SolutionsOnly
+)
+
+var solutionsNames = [...]string{
+	SolutionsDefault: "default",
+	SolutionsNone:    "none",
+	SolutionsInline:  "inline",
+	SolutionsSection: "section",
+	SolutionsFile:    "file",
+	SolutionsOnly:    "only",
+}
+
+// String returns the name ParseSolutions accepts.
+func (s Solutions) String() string {
+	if s < 0 || int(s) >= len(solutionsNames) {
+		return fmt.Sprintf("Solutions(%d)", int(s))
+	}
+	return solutionsNames[s]
+}
+
+// ParseSolutions parses none, inline, section or file; "" is
+// SolutionsDefault.
+func ParseSolutions(s string) (Solutions, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "default":
+		return SolutionsDefault, nil
+	case "none":
+		return SolutionsNone, nil
+	case "inline":
+		return SolutionsInline, nil
+	case "section", "back":
+		return SolutionsSection, nil
+	case "file", "separate":
+		return SolutionsFile, nil
+	}
+	return 0, fmt.Errorf("unknown solutions placement %q: must be none, inline, section or file", s)
+}
+
+// Options configures a Writer.
+type Options struct {
+	// Solutions is where solutions go; Open resolves SolutionsDefault and
+	// SolutionsFile before a format's New sees them.
+	Solutions Solutions
+	// Seed is the seed of the run, shown by formats that print it.
+	Seed int64
+	// Theme styles HTML output; nil uses plain defaults.
+	Theme *theme.Theme
+	// Template, if set, replaces the built-in HTML template.
+	Template *Template
+	// MinClues and MaxClues are the requested clue range. When Annotate is
+	// set, text output notes puzzles whose clue count falls outside it, as
+	// minimal puzzles may.
+	MinClues, MaxClues int
+	Annotate           bool
+	// Candidates, CellSize, Shade and Captions configure the image formats:
+	// pencil marks in empty cells, the width of a cell in pixels (0 for the
+	// default), pastel region shading and a caption with the puzzle number
+	// and difficulty.
+	Candidates bool
+	CellSize   int
+	Shade      bool
+	Captions   bool
+	// PageSize and PerPage lay out paged formats: the paper size and how
+	// many puzzles share a page (1, 2, 4 or 6). Zero values mean A4 and one.
+	PageSize PageSize
+	PerPage  int
+}
+
+// PageSize is a paper size in points (1/72 inch), portrait.
+type PageSize struct {
+	Name          string
+	Width, Height float64
+}
+
+// Page sizes accepted by ParsePageSize.
+var (
+	A4     = PageSize{"a4", 595.28, 841.89}
+	A5     = PageSize{"a5", 419.53, 595.28}
+	Letter = PageSize{"letter", 612, 792}
+)
+
+// ParsePageSize parses a4, a5 or letter; "" is A4.
+func ParsePageSize(s string) (PageSize, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "a4":
+		return A4, nil
+	case "a5":
+		return A5, nil
+	case "letter":
+		return Letter, nil
+	}
+	return PageSize{}, fmt.Errorf("unknown page size %q: must be a4, a5 or letter", s)
+}
+
+// ValidPerPage reports whether n puzzles can share a page.
+func ValidPerPage(n int) bool {
+	switch n {
+	case 1, 2, 4, 6:
+		return true
+	}
+	return false
+}
+
+// Writer writes the puzzles of one run.
+type Writer interface {
+	// Write adds a puzzle. Puzzles arrive in Number order.
+	Write(p Puzzle) error
+	// Close finishes the output. It does not close the underlying file.
+	Close() error
+}
+
+// Format is a registered output format.
+type Format struct {
+	Name        string
+	Description string
+	// Extensions are the file extensions, with the dot, that select the
+	// format; the first is used when a file name needs one.
+	Extensions []string
+	// Solutions is where the format puts solutions by default.
+	Solutions Solutions
+	// Themed formats are styled by Options.Theme.
+	Themed bool
+	// PerPuzzle formats write each puzzle to a file of its own, named by
+	// PuzzlePath. With no room for solutions inline or in a section, they
+	// always put solutions in separate files.
+	PerPuzzle bool
+	// New creates a writer for the format that writes to w.
+	New func(w io.Writer, opts Options) Writer
+}
+
+var formats = map[string]Format{}
+
+// Register adds a format, replacing any format of the same name.
+func Register(f Format) {
+	formats[f.Name] = f
+}
+
+// Lookup returns the format with the given name.
+func Lookup(name string) (Format, bool) {
+	f, ok := formats[strings.ToLower(name)]
+	return f, ok
+}
+
+// ForPath returns the format selected by the extension of path.
+func ForPath(path string) (Format, bool) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == "" {
+		return Format{}, false
+	}
+	for _, f := range Formats() {
+		for _, e := range f.Extensions {
+			if e == ext {
+				return f, true
+			}
+		}
+	}
+	return Format{}, false
+}
+
+// Formats returns the registered formats sorted by name.
+func Formats() []Format {
+	list := make([]Format, 0, len(formats))
+	for _, f := range formats {
+		list = append(list, f)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Names returns the names of the registered formats, sorted.
+func Names() []string {
+	var names []string
+	for _, f := range Formats() {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+// SolutionsPath returns the name of the separate solutions file for path,
+// e.g. "book-solutions.html" for "book.html".
+func SolutionsPath(path string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "-solutions" + ext
+}
+
+// Placement returns where the format puts solutions when asked for s:
+// SolutionsDefault becomes the format's own choice, and PerPuzzle formats
+// move inline and section solutions to a separate file.
+func (f Format) Placement(s Solutions) Solutions {
+	if s == SolutionsDefault {
+		s = f.Solutions
+	}
+	if f.PerPuzzle && (s == SolutionsInline || s == SolutionsSection) {
+		s = SolutionsFile
+	}
+	return s
+}
+
+// PuzzlePath returns the name of the file for puzzle n of a PerPuzzle
+// format, e.g. "puzzles-007.svg" for "puzzles.svg".
+func PuzzlePath(path string, n int) string {
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s-%03d%s", strings.TrimSuffix(path, ext), n, ext)
+}
+
+// Open creates a writer for format f that writes to path, or to stdout when
+// path is "". With SolutionsFile a second writer of the same format writes
+// the solutions to SolutionsPath(path). On stdout, a format whose default is
+// SolutionsFile writes the puzzles alone.
+func Open(f Format, path string, opts Options) (*Output, error) {
+	requested := opts.Solutions
+	opts.Solutions = f.Placement(opts.Solutions)
+	if opts.Solutions == SolutionsFile && path == "" {
+		if requested != SolutionsDefault {
+			return nil, fmt.Errorf("a separate solutions file needs an output file")
+		}
+		opts.Solutions = SolutionsNone
+	}
+
+	out := &Output{}
+	create := func(name string, opts Options) error {
+		if f.PerPuzzle {
+			out.writers = append(out.writers, &perPuzzle{format: f, path: name, opts: opts, out: out})
+			return nil
+		}
+		if name == "" {
+			out.writers = append(out.writers, f.New(os.Stdout, opts))
+			return nil
+		}
+		file, err := os.Create(name)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		out.files = append(out.files, file)
+		out.created = append(out.created, name)
+		out.writers = append(out.writers, f.New(file, opts))
+		return nil
+	}
+
+	main := opts
+	if opts.Solutions == SolutionsFile {
+		main.Solutions = SolutionsNone
+	}
+	if err := create(path, main); err != nil {
+		return nil, err
+	}
+	if opts.Solutions == SolutionsFile {
+		answers := opts
+		answers.Solutions = SolutionsOnly
+		if err := create(SolutionsPath(path), answers); err != nil {
+			out.Abort()
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// Output is an open output: the writers of a format and the files they
+// write to. It implements Writer.
+type Output struct {
+	writers []Writer
+	files   []*os.File // open until Close
+	created []string   // every file written, removed by Abort
+	closed  bool
+}
+
+// Write passes p to every writer.
+func (o *Output) Write(p Puzzle) error {
+	for _, w := range o.writers {
+		if err := w.Write(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close finishes the output and closes its files.
+func (o *Output) Close() error {
+	if o.closed {
+		return nil
+	}
+	o.closed = true
+	var first error
+	for _, w := range o.writers {
+		if err := w.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	for _, f := range o.files {
+		if err := f.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Abort closes and removes the output files without finishing them. It does
+// nothing after Close, so it can be deferred to clean up after failures.
+func (o *Output) Abort() {
+	if o.closed {
+		return
+	}
+	o.closed = true
+	for _, f := range o.files {
+		f.Close()
+	}
+	for _, name := range o.created {
+		os.Remove(name)
+	}
+}
+
+// perPuzzle writes each puzzle of a PerPuzzle format to a new file, or all
+// of them in turn to stdout when there is no path.
+type perPuzzle struct {
+	format Format
+	path   string
+	opts   Options
+	out    *Output
+}
+
+func (p *perPuzzle) Write(pz Puzzle) error {
+	if p.path == "" {
+		w := p.format.New(os.Stdout, p.opts)
+		if err := w.Write(pz); err != nil {
+			return err
+		}
+		return w.Close()
+	}
+
+	name := PuzzlePath(p.path, pz.Number)
+	file, err := os.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	p.out.created = append(p.out.created, name)
+	w := p.format.New(file, p.opts)
+	err = w.Write(pz)
+	if err == nil {
+		err = w.Close()
+	}
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (p *perPuzzle) Close() error {
+	return nil
+}
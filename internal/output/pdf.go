@@ -0,0 +1,310 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/rybkr/sudoku/internal/board"
+	"github.com/rybkr/sudoku/internal/pdf"
+)
+
+func init() {
+	Register(Format{
+		Name:        "pdf",
+		Description: "A printable PDF booklet with a title page and answer key, easiest first",
+		Extensions:  []string{".pdf"},
+		Solutions:   SolutionsSection,
+		New: func(w io.Writer, opts Options) Writer {
+			return &pdfWriter{w: w, opts: opts}
+		},
+	})
+}
+
+// pdfWriter collects the puzzles and lays out the booklet on Close, sorted
+// by difficulty like the HTML booklet: a title page, the puzzles at
+// Options.PerPage to a page, then an answer key of smaller grids.
+type pdfWriter struct {
+	w       io.Writer
+	opts    Options
+	puzzles []Puzzle
+}
+
+// pdfTile is one grid of the booklet with its heading. Solutions carry the
+// puzzle they solve so the givens can be told from the filled-in digits.
+type pdfTile struct {
+	heading string
+	note    string
+	board   *board.Board
+	givens  *board.Board
+}
+
+func (p *pdfWriter) Write(pz Puzzle) error {
+	p.puzzles = append(p.puzzles, pz)
+	return nil
+}
+
+func (p *pdfWriter) Close() error {
+	size := p.opts.PageSize
+	if size.Name == "" {
+		size = A4
+	}
+	perPage := p.opts.PerPage
+	if !ValidPerPage(perPage) {
+		perPage = 1
+	}
+
+	puzzles := bookOrder(p.puzzles)
+
+	// Puzzles are renumbered in booklet order; solutions keep the number of
+	// the puzzle they solve.
+	var main, answers []pdfTile
+	for i, pz := range puzzles {
+		puzzle := pdfTile{
+			heading: pz.label("Puzzle", i+1),
+			note:    pz.Rating.String(),
+			board:   pz.Puzzle,
+		}
+		solution := pdfTile{
+			heading: pz.label("Solution", i+1),
+			board:   pz.Solution,
+			givens:  pz.Puzzle,
+		}
+		switch p.opts.Solutions {
+		case SolutionsInline:
+			main = append(main, puzzle, solution)
+		case SolutionsSection:
+			main = append(main, puzzle)
+			answers = append(answers, solution)
+		case SolutionsOnly:
+			answers = append(answers, solution)
+		default:
+			main = append(main, puzzle)
+		}
+	}
+
+	// The answer key fits twelve grids on a full-size page and six on A5.
+	answerCols, answerRows := 3, 4
+	if size.Width < A4.Width {
+		answerCols, answerRows = 2, 3
+	}
+
+	b := newPDFBook(size)
+	cols, rows := pdfGrid(perPage)
+	mainPages := (len(main) + perPage - 1) / perPage
+	b.titlePage(puzzles, p.opts, mainPages)
+	b.tiles(main, cols, rows)
+	b.tiles(answers, answerCols, answerRows)
+
+	_, err := b.doc.WriteTo(p.w)
+	return err
+}
+
+// pdfGrid returns the columns and rows of tiles that put n on a page.
+func pdfGrid(n int) (cols, rows int) {
+	switch n {
+	case 2:
+		return 1, 2
+	case 4:
+		return 2, 2
+	case 6:
+		return 2, 3
+	}
+	return 1, 1
+}
+
+// pdfBook lays out booklet pages of one size.
+type pdfBook struct {
+	doc    *pdf.Document
+	size   PageSize
+	margin float64
+	gap    float64
+	pages  int
+}
+
+func newPDFBook(size PageSize) *pdfBook {
+	b := &pdfBook{doc: pdf.New(), size: size, margin: 36, gap: 18}
+	if size.Width < A4.Width {
+		b.margin, b.gap = 28, 12
+	}
+	return b
+}
+
+// addPage starts a page and, except on the title page, numbers it in the
+// footer.
+func (b *pdfBook) addPage() *pdf.Page {
+	page := b.doc.AddPage(b.size.Width, b.size.Height)
+	b.pages++
+	if b.pages > 1 {
+		page.SetFillGray(0.4)
+		page.TextCentered(b.size.Width/2, b.size.Height-b.margin/2+3, pdf.Helvetica, 9, strconv.Itoa(b.pages))
+		page.SetFillGray(0)
+	}
+	return page
+}
+
+// titlePage describes the booklet: what it holds, how hard it is, where
+// the answers start and the seed that reproduces it.
+func (b *pdfBook) titlePage(puzzles []Puzzle, opts Options, mainPages int) {
+	title := "Sudoku Puzzles"
+	if len(puzzles) > 0 && puzzles[0].Puzzle.Layout().Type == "jigsaw" {
+		title = "Jigsaw Sudoku Puzzles"
+	}
+	if opts.Solutions == SolutionsOnly {
+		title = "Sudoku Solutions"
+	}
+	b.doc.Title = title
+
+	page := b.addPage()
+	center := b.size.Width / 2
+	scale := b.size.Width / A4.Width
+	y := b.size.Height * 0.35
+
+	page.TextCentered(center, y, pdf.HelveticaBold, 32*scale, title)
+	y += 40 * scale
+
+	lines := []string{plural(len(puzzles), "puzzle")}
+	if n := len(puzzles); n > 0 {
+		// Daily puzzles are in date order, so look for the extremes.
+		easiest, hardest := puzzles[0].Rating, puzzles[0].Rating
+		for _, pz := range puzzles[1:] {
+			if pz.Rating.Score < easiest.Score {
+				easiest = pz.Rating
+			}
+			if pz.Rating.Score > hardest.Score {
+				hardest = pz.Rating
+			}
+		}
+		if n == 1 {
+			lines = append(lines, "Difficulty: "+easiest.String())
+		} else {
+			lines = append(lines, fmt.Sprintf("Difficulty: %s to %s", easiest, hardest))
+		}
+	}
+	switch opts.Solutions {
+	case SolutionsSection:
+		lines = append(lines, fmt.Sprintf("Solutions from page %d", 2+mainPages))
+	case SolutionsInline:
+		lines = append(lines, "Each solution follows its puzzle")
+	}
+	for _, line := range lines {
+		page.TextCentered(center, y, pdf.Helvetica, 14*scale, line)
+		y += 22 * scale
+	}
+
+	if opts.Seed != 0 {
+		page.SetFillGray(0.4)
+		page.TextCentered(center, b.size.Height-b.margin, pdf.Helvetica, 9, fmt.Sprintf("Seed: %d", opts.Seed))
+		page.SetFillGray(0)
+	}
+}
+
+// plural formats a count of things, e.g. "1 puzzle" or "6 puzzles".
+func plural(n int, thing string) string {
+	if n == 1 {
+		return "1 " + thing
+	}
+	return fmt.Sprintf("%d %ss", n, thing)
+}
+
+// tiles lays out the tiles cols by rows to a page, left to right and top to
+// bottom, starting a new page as each fills.
+func (b *pdfBook) tiles(tiles []pdfTile, cols, rows int) {
+	areaW := b.size.Width - 2*b.margin
+	areaH := b.size.Height - 2*b.margin
+	tileW := (areaW - float64(cols-1)*b.gap) / float64(cols)
+	tileH := (areaH - float64(rows-1)*b.gap) / float64(rows)
+
+	var page *pdf.Page
+	for i, t := range tiles {
+		slot := i % (cols * rows)
+		if slot == 0 {
+			page = b.addPage()
+		}
+		x := b.margin + float64(slot%cols)*(tileW+b.gap)
+		y := b.margin + float64(slot/cols)*(tileH+b.gap)
+		drawTile(page, t, x, y, tileW, tileH)
+	}
+}
+
+// drawTile draws the heading and grid of t, as large as they fit in the
+// w by h box at (x, y) and centred in it.
+func drawTile(page *pdf.Page, t pdfTile, x, y, w, h float64) {
+	headSize := math.Min(14, w/22)
+	headH := headSize * 1.8
+	size := math.Min(w, h-headH)
+
+	gx := x + (w-size)/2
+	top := y + (h-headH-size)/2
+	gy := top + headH
+
+	page.Text(gx, top+headSize, pdf.HelveticaBold, headSize, t.heading)
+	if t.note != "" {
+		noteSize := headSize * 0.85
+		page.Text(gx+size-pdf.TextWidth(pdf.Helvetica, noteSize, t.note), top+headSize, pdf.Helvetica, noteSize, t.note)
+	}
+	drawGrid(page, t, gx, gy, size)
+}
+
+// drawGrid draws a board size points square at (x, y). Cell lines are thin
+// and gray; every side that Layout.Borders marks as a region boundary is
+// drawn thick on top, so jigsaw regions are outlined as in the HTML
+// booklet. In a solution the givens are bold and the rest gray.
+func drawGrid(page *pdf.Page, t pdfTile, x, y, size float64) {
+	cell := size / 9
+
+	page.SetStrokeGray(0.55)
+	page.SetLineWidth(math.Max(size/800, 0.25))
+	for i := 1; i < 9; i++ {
+		off := float64(i) * cell
+		page.Line(x+off, y, x+off, y+size)
+		page.Line(x, y+off, x+size, y+off)
+	}
+
+	// A boundary between two cells is marked on both, so the top and left
+	// sides of every cell cover the inside and the last row and column add
+	// the outer edge.
+	page.SetStrokeGray(0)
+	page.SetLineWidth(math.Max(size/200, 1))
+	layout := t.board.Layout()
+	for pos := range board.CellCount {
+		row, col := pos/9, pos%9
+		cx, cy := x+float64(col)*cell, y+float64(row)*cell
+		borders := layout.Borders(pos)
+		if borders&board.BorderTop != 0 {
+			page.Line(cx, cy, cx+cell, cy)
+		}
+		if borders&board.BorderLeft != 0 {
+			page.Line(cx, cy, cx, cy+cell)
+		}
+		if row == 8 {
+			page.Line(cx, cy+cell, cx+cell, cy+cell)
+		}
+		if col == 8 {
+			page.Line(cx+cell, cy, cx+cell, cy+cell)
+		}
+	}
+
+	// Digits are about 0.7 em tall, so this baseline centres them.
+	fontSize := cell * 0.6
+	for pos := range board.CellCount {
+		val := t.board.Get(pos)
+		if val == board.EmptyCell {
+			continue
+		}
+		font, gray := pdf.Helvetica, 0.0
+		if t.givens != nil {
+			if t.givens.Get(pos) != board.EmptyCell {
+				font = pdf.HelveticaBold
+			} else {
+				gray = 0.4
+			}
+		}
+		page.SetFillGray(gray)
+		cx := x + float64(pos%9)*cell + cell/2
+		cy := y + float64(pos/9)*cell + cell/2 + fontSize*0.35
+		page.TextCentered(cx, cy, font, fontSize, strconv.Itoa(val))
+	}
+	page.SetFillGray(0)
+}
@@ -0,0 +1,34 @@
+package output
+
+import (
+	"io"
+
+	"github.com/rybkr/sudoku/internal/render"
+)
+
+func init() {
+	Register(Format{
+		Name:        "png",
+		Description: "One PNG image per puzzle, with its solution in another",
+		Extensions:  []string{".png"},
+		Solutions:   SolutionsFile,
+		PerPuzzle:   true,
+		New: func(w io.Writer, opts Options) Writer {
+			return &pngWriter{w: w, opts: opts}
+		},
+	})
+}
+
+// pngWriter draws a puzzle as a PNG image, as svgWriter does as SVG.
+type pngWriter struct {
+	w    io.Writer
+	opts Options
+}
+
+func (p *pngWriter) Write(pz Puzzle) error {
+	return render.PNG(p.w, pz.Puzzle, imageOptions(pz, p.opts))
+}
+
+func (p *pngWriter) Close() error {
+	return nil
+}
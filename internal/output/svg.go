@@ -0,0 +1,62 @@
+package output
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/rybkr/sudoku/internal/render"
+)
+
+func init() {
+	Register(Format{
+		Name:        "svg",
+		Description: "One standalone SVG image per puzzle, with its solution in another",
+		Extensions:  []string{".svg"},
+		Solutions:   SolutionsFile,
+		PerPuzzle:   true,
+		New: func(w io.Writer, opts Options) Writer {
+			return &svgWriter{w: w, opts: opts}
+		},
+	})
+}
+
+// svgWriter draws a puzzle as an SVG image. A solution image shows the
+// puzzle with the rest of the grid filled in, styled apart from the givens.
+type svgWriter struct {
+	w    io.Writer
+	opts Options
+}
+
+func (s *svgWriter) Write(p Puzzle) error {
+	return render.SVG(s.w, p.Puzzle, imageOptions(p, s.opts))
+}
+
+func (s *svgWriter) Close() error {
+	return nil
+}
+
+// imageOptions returns the rendering of p that opts ask for; the SVG and PNG
+// formats draw the same picture.
+func imageOptions(p Puzzle, opts Options) render.Options {
+	ro := render.Options{CellSize: opts.CellSize, Shade: opts.Shade}
+	if opts.Solutions == SolutionsOnly {
+		ro.Solution = p.Solution
+	} else if opts.Candidates {
+		ro.Candidates = render.CandidateMasks(p.Puzzle)
+	}
+	if opts.Captions {
+		ro.Title = p.label("Puzzle", p.Number)
+		if opts.Solutions == SolutionsOnly {
+			ro.Title = p.label("Solution", p.Number)
+		}
+		ro.Subtitle = p.Rating.String()
+	}
+	// Images have no room for a header, so the seed that regenerates the
+	// puzzle goes into their metadata.
+	if opts.Seed != 0 {
+		ro.Description = fmt.Sprintf("Puzzle #%d of seed %d", p.Number, opts.Seed)
+	} else if p.Date != "" {
+		ro.Description = p.label("Puzzle", p.Number)
+	}
+	return ro
+}
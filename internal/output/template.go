@@ -0,0 +1,89 @@
+package output
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// templateContext is how many lines either side of an error are quoted.
+const templateContext = 2
+
+// Template is a user's HTML template for the html format, given the same
+// TemplateData as the built-in one. It keeps its source so that errors can
+// quote the lines they refer to.
+type Template struct {
+	tmpl  *template.Template
+	name  string
+	lines []string
+}
+
+// ParseTemplate reads and parses the template in file path. It also runs the
+// template once on sample data, so that references to fields TemplateData
+// and PuzzlePage lack are reported before any puzzles are generated.
+func ParseTemplate(path string) (*Template, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %w", err)
+	}
+	t := &Template{
+		name:  filepath.Base(path),
+		lines: strings.Split(string(src), "\n"),
+	}
+	t.tmpl, err = template.New(t.name).Parse(string(src))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", t.withContext(err))
+	}
+
+	page := []PuzzlePage{{Title: "Sudoku Puzzle", PuzzleNumber: 1}}
+	sample := TemplateData{
+		PuzzlePages: page,
+		Sheets:      [][]PuzzlePage{page},
+		PerPage:     1,
+		PageSize:    A4.Name,
+		AnswerPages: [][]PuzzlePage{page},
+	}
+	if err := t.tmpl.Execute(io.Discard, sample); err != nil {
+		return nil, fmt.Errorf("invalid template: %w", t.withContext(err))
+	}
+	return t, nil
+}
+
+// Execute renders data to w.
+func (t *Template) Execute(w io.Writer, data TemplateData) error {
+	if err := t.tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to execute template: %w", t.withContext(err))
+	}
+	return nil
+}
+
+// withContext appends to err the lines of the template around the line it
+// names, if any, e.g. "template: book.html:12: ..." quotes lines 10 to 14
+// and marks line 12.
+func (t *Template) withContext(err error) error {
+	m := regexp.MustCompile(regexp.QuoteMeta(t.name) + `:(\d+)`).FindStringSubmatch(err.Error())
+	if m == nil {
+		return err
+	}
+	line, _ := strconv.Atoi(m[1])
+	if line < 1 || line > len(t.lines) {
+		return err
+	}
+
+	var sb strings.Builder
+	first, last := max(1, line-templateContext), min(len(t.lines), line+templateContext)
+	width := len(strconv.Itoa(last))
+	for n := first; n <= last; n++ {
+		marker := " "
+		if n == line {
+			marker = ">"
+		}
+		fmt.Fprintf(&sb, "\n%s %*d | %s", marker, width, n, t.lines[n-1])
+	}
+	return fmt.Errorf("%w%s", err, sb.String())
+}
@@ -0,0 +1,81 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+func init() {
+	Register(Format{
+		Name:        "text",
+		Description: "Plain-text grids, as printed to the console",
+		Extensions:  []string{".txt"},
+		Solutions:   SolutionsInline,
+		New: func(w io.Writer, opts Options) Writer {
+			return &textWriter{w: w, opts: opts}
+		},
+	})
+}
+
+// textWriter prints each puzzle as an ASCII grid under a one-line header.
+type textWriter struct {
+	w       io.Writer
+	opts    Options
+	started bool
+	pending []Puzzle // solutions held back for SolutionsSection
+}
+
+func (t *textWriter) Write(p Puzzle) error {
+	if !t.started {
+		t.started = true
+		if t.opts.Seed != 0 {
+			fmt.Fprintf(t.w, "Seed: %d\n\n", t.opts.Seed)
+		}
+	}
+
+	if t.opts.Solutions == SolutionsOnly {
+		fmt.Fprintf(t.w, "%s:\n%s\n\n", p.label("Solution", p.Number), p.Solution.Format())
+		return nil
+	}
+
+	clues := strconv.Itoa(p.Puzzle.ClueCount())
+	if t.opts.Annotate {
+		clues += clueOffset(p.Puzzle.ClueCount(), t.opts.MinClues, t.opts.MaxClues)
+	}
+	fmt.Fprintf(t.w, "%s (Clues: %s, Difficulty: %s):\n", p.label("Puzzle", p.Number), clues, p.Rating)
+	fmt.Fprintln(t.w, p.Puzzle.Format())
+	switch t.opts.Solutions {
+	case SolutionsInline:
+		fmt.Fprintln(t.w, "\nSolution:")
+		fmt.Fprintln(t.w, p.Solution.Format())
+	case SolutionsSection:
+		t.pending = append(t.pending, p)
+	}
+	_, err := fmt.Fprintln(t.w)
+	return err
+}
+
+func (t *textWriter) Close() error {
+	if len(t.pending) == 0 {
+		return nil
+	}
+	fmt.Fprintln(t.w, "Solutions:")
+	fmt.Fprintln(t.w)
+	for _, p := range t.pending {
+		fmt.Fprintf(t.w, "%s:\n%s\n\n", p.label("Solution", p.Number), p.Solution.Format())
+	}
+	return nil
+}
+
+// clueOffset describes how far a clue count lies from the requested range,
+// e.g. ", 3 below 28", or returns "" when it lies inside the range.
+func clueOffset(clues, minClues, maxClues int) string {
+	switch {
+	case clues < minClues:
+		return fmt.Sprintf(", %d below %d", minClues-clues, minClues)
+	case clues > maxClues:
+		return fmt.Sprintf(", %d above %d", clues-maxClues, maxClues)
+	}
+	return ""
+}
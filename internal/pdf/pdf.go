@@ -0,0 +1,205 @@
+// Package pdf writes simple vector PDF documents: lines, rectangles and text
+// in the standard Helvetica fonts, which every PDF reader provides, so
+// nothing needs to be embedded. It depends only on the standard library.
+//
+// Page coordinates are in points (1/72 inch) with the origin at the top-left
+// corner and y growing downwards; the package converts them to PDF's
+// bottom-left origin.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Font is one of the standard fonts.
+type Font int
+
+const (
+	Helvetica Font = iota
+	HelveticaBold
+)
+
+// fontNames are the PostScript names of the fonts, in Font order.
+var fontNames = [...]string{
+	Helvetica:     "Helvetica",
+	HelveticaBold: "Helvetica-Bold",
+}
+
+// Document is a PDF under construction.
+type Document struct {
+	// Title is recorded in the document information dictionary.
+	Title string
+	pages []*Page
+}
+
+// New returns an empty document.
+func New() *Document {
+	return &Document{}
+}
+
+// AddPage appends a page of the given size in points and returns it.
+func (d *Document) AddPage(width, height float64) *Page {
+	p := &Page{width: width, height: height}
+	// Square line caps make thick lines meet cleanly at corners.
+	p.op("2 J")
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// Page is one page of a Document. Its drawing methods append to the page's
+// content stream.
+type Page struct {
+	width, height float64
+	content       bytes.Buffer
+}
+
+// Width returns the page width in points.
+func (p *Page) Width() float64 { return p.width }
+
+// Height returns the page height in points.
+func (p *Page) Height() float64 { return p.height }
+
+// op appends one content stream operation.
+func (p *Page) op(format string, args ...any) {
+	fmt.Fprintf(&p.content, format, args...)
+	p.content.WriteByte('\n')
+}
+
+// y converts a top-down coordinate to PDF's bottom-up one.
+func (p *Page) y(y float64) float64 {
+	return p.height - y
+}
+
+// SetLineWidth sets the width of stroked lines.
+func (p *Page) SetLineWidth(w float64) {
+	p.op("%s w", num(w))
+}
+
+// SetStrokeGray sets the stroke colour to a gray level from 0 (black) to 1
+// (white).
+func (p *Page) SetStrokeGray(g float64) {
+	p.op("%s G", num(g))
+}
+
+// SetFillGray sets the fill colour, used for text and filled shapes, to a
+// gray level from 0 (black) to 1 (white).
+func (p *Page) SetFillGray(g float64) {
+	p.op("%s g", num(g))
+}
+
+// Line strokes a line from (x1, y1) to (x2, y2). Lines end in square caps.
+func (p *Page) Line(x1, y1, x2, y2 float64) {
+	p.op("%s %s m %s %s l S", num(x1), num(p.y(y1)), num(x2), num(p.y(y2)))
+}
+
+// Rect strokes the outline of the rectangle with top-left corner (x, y).
+func (p *Page) Rect(x, y, w, h float64) {
+	p.op("%s %s %s %s re S", num(x), num(p.y(y+h)), num(w), num(h))
+}
+
+// FillRect fills the rectangle with top-left corner (x, y).
+func (p *Page) FillRect(x, y, w, h float64) {
+	p.op("%s %s %s %s re f", num(x), num(p.y(y+h)), num(w), num(h))
+}
+
+// Text draws s with its baseline starting at (x, y).
+func (p *Page) Text(x, y float64, font Font, size float64, s string) {
+	p.op("BT /F%d %s Tf %s %s Td %s Tj ET", font+1, num(size), num(x), num(p.y(y)), literal(s))
+}
+
+// TextCentered draws s centred horizontally on x with its baseline at y.
+func (p *Page) TextCentered(x, y float64, font Font, size float64, s string) {
+	p.Text(x-TextWidth(font, size, s)/2, y, font, size, s)
+}
+
+// WriteTo writes the document to w.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	var offsets []int
+
+	// obj starts object n, which must be the next in sequence.
+	obj := func(body string) int {
+		offsets = append(offsets, buf.Len())
+		n := len(offsets)
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", n, body)
+		return n
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Fixed object numbers: 1 catalog, 2 page tree, 3 info, then one font
+	// object per Font; pages and their contents follow in pairs.
+	const pagesObj = 2
+	firstPage := 4 + len(fontNames)
+
+	obj(fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesObj))
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+
+	obj(fmt.Sprintf("<< /Title %s /Producer (sudoku) >>", literal(d.Title)))
+
+	var fonts []string
+	for i, name := range fontNames {
+		n := obj(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name))
+		fonts = append(fonts, fmt.Sprintf("/F%d %d 0 R", i+1, n))
+	}
+	resources := fmt.Sprintf("<< /Font << %s >> >>", strings.Join(fonts, " "))
+
+	for i, p := range d.pages {
+		obj(fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources %s /Contents %d 0 R >>",
+			pagesObj, num(p.width), num(p.height), resources, firstPage+2*i+1))
+
+		var z bytes.Buffer
+		zw := zlib.NewWriter(&z)
+		zw.Write(p.content.Bytes())
+		zw.Close()
+		obj(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", z.Len(), z.Bytes()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 3 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.WriteTo(w)
+}
+
+// num formats a coordinate compactly with at most two decimals.
+func num(f float64) string {
+	s := strconv.FormatFloat(f, 'f', 2, 64)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// literal encodes s as a PDF string in WinAnsiEncoding. Characters outside
+// Latin-1 become '?'.
+func literal(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('(')
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r >= 32 && r < 127:
+			sb.WriteRune(r)
+		case r >= 160 && r <= 255:
+			fmt.Fprintf(&sb, "\\%03o", r)
+		default:
+			sb.WriteByte('?')
+		}
+	}
+	sb.WriteByte(')')
+	return sb.String()
+}
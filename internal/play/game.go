@@ -0,0 +1,322 @@
+package play
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rybkr/sudoku/internal/board"
+	"github.com/rybkr/sudoku/internal/solver"
+)
+
+// cell is the player-visible state of one square.
+type cell struct {
+	digit int  // placed digit, or board.EmptyCell
+	marks uint // pencil marks; bit i represents digit i+1
+}
+
+// edit records one cell's state before and after a move.
+type edit struct {
+	pos           int
+	before, after cell
+}
+
+// move is a single undoable action. Placing a digit also clears that digit
+// from the pencil marks of every peer, so a move may touch several cells.
+type move []edit
+
+// Game is an interactive Sudoku session: the puzzle, the player's entries
+// and pencil marks, the cursor, undo history and timer. It knows nothing
+// about terminals; Run drives it from key presses.
+type Game struct {
+	puzzle   *board.Board
+	solution *board.Board
+	cells    [board.CellCount]cell
+
+	cursor int
+	pencil bool // digits toggle pencil marks instead of placing
+
+	undo, redo []move
+
+	// hint holds the cells highlighted by the last hint, if any.
+	hint    []int
+	message string
+
+	start    time.Time
+	finished time.Duration // time taken, once solved
+	solved   bool
+}
+
+// NewGame starts a session for a puzzle, which must have a unique solution.
+func NewGame(puzzle *board.Board) (*Game, error) {
+	opts := solver.DefaultOptions()
+	opts.MaxSolutions = 2
+	opts.Engine = solver.DancingLinks
+	solution, err := solver.New(puzzle, opts).SolveContext(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("cannot play this puzzle: %w", err)
+	}
+
+	g := &Game{
+		puzzle:   puzzle.Clone(),
+		solution: solution,
+		start:    time.Now(),
+	}
+	for pos := range board.CellCount {
+		g.cells[pos].digit = puzzle.Get(pos)
+	}
+	// Start on the first empty cell.
+	for pos := range board.CellCount {
+		if g.cells[pos].digit == board.EmptyCell {
+			g.cursor = pos
+			break
+		}
+	}
+	return g, nil
+}
+
+// Elapsed returns the time played so far, or the solving time once solved.
+func (g *Game) Elapsed() time.Duration {
+	if g.solved {
+		return g.finished
+	}
+	return time.Since(g.start)
+}
+
+// Solved reports whether every cell holds the correct digit.
+func (g *Game) Solved() bool {
+	return g.solved
+}
+
+// Remaining returns the number of empty cells.
+func (g *Game) Remaining() int {
+	n := 0
+	for _, c := range g.cells {
+		if c.digit == board.EmptyCell {
+			n++
+		}
+	}
+	return n
+}
+
+// given reports whether pos holds one of the puzzle's clues.
+func (g *Game) given(pos int) bool {
+	return g.puzzle.Get(pos) != board.EmptyCell
+}
+
+// Move shifts the cursor, wrapping around the edges of the grid.
+func (g *Game) Move(dRow, dCol int) {
+	row, col := g.cursor/9, g.cursor%9
+	g.cursor = board.MakePos((row+dRow+9)%9, (col+dCol+9)%9)
+}
+
+// Enter places digit at the cursor, or toggles it as a pencil mark in
+// pencil mode.
+func (g *Game) Enter(digit int) {
+	if g.pencil {
+		g.ToggleMark(digit)
+		return
+	}
+	if g.solved || g.given(g.cursor) || g.cells[g.cursor].digit == digit {
+		return
+	}
+
+	m := move{g.change(g.cursor, cell{digit: digit})}
+	// Placing a digit rules it out for every peer.
+	for pos := range board.CellCount {
+		if pos != g.cursor && g.peers(pos, g.cursor) && g.cells[pos].marks&digitMask(digit) != 0 {
+			c := g.cells[pos]
+			c.marks &^= digitMask(digit)
+			m = append(m, g.change(pos, c))
+		}
+	}
+	g.commit(m)
+}
+
+// ToggleMark adds or removes a pencil mark at the cursor. Cells holding a
+// digit keep no marks.
+func (g *Game) ToggleMark(digit int) {
+	c := g.cells[g.cursor]
+	if g.solved || c.digit != board.EmptyCell {
+		return
+	}
+	c.marks ^= digitMask(digit)
+	g.commit(move{g.change(g.cursor, c)})
+}
+
+// Clear removes the digit, or failing that the pencil marks, at the cursor.
+func (g *Game) Clear() {
+	c := g.cells[g.cursor]
+	if g.solved || g.given(g.cursor) || c == (cell{}) {
+		return
+	}
+	if c.digit != board.EmptyCell {
+		c.digit = board.EmptyCell
+	} else {
+		c.marks = 0
+	}
+	g.commit(move{g.change(g.cursor, c)})
+}
+
+// TogglePencil switches between entering digits and pencil marks.
+func (g *Game) TogglePencil() {
+	g.pencil = !g.pencil
+}
+
+// Undo reverts the last move.
+func (g *Game) Undo() {
+	if g.solved || len(g.undo) == 0 {
+		return
+	}
+	m := g.undo[len(g.undo)-1]
+	g.undo = g.undo[:len(g.undo)-1]
+	for i := len(m) - 1; i >= 0; i-- {
+		g.cells[m[i].pos] = m[i].before
+	}
+	g.cursor = m[0].pos
+	g.redo = append(g.redo, m)
+	g.hint, g.message = nil, ""
+}
+
+// Redo reapplies the last undone move.
+func (g *Game) Redo() {
+	if g.solved || len(g.redo) == 0 {
+		return
+	}
+	m := g.redo[len(g.redo)-1]
+	g.redo = g.redo[:len(g.redo)-1]
+	for _, e := range m {
+		g.cells[e.pos] = e.after
+	}
+	g.cursor = m[0].pos
+	g.undo = append(g.undo, m)
+	g.hint, g.message = nil, ""
+	g.checkSolved()
+}
+
+// change returns the edit that sets pos to c.
+func (g *Game) change(pos int, c cell) edit {
+	return edit{pos: pos, before: g.cells[pos], after: c}
+}
+
+// commit applies a new move and records it for undo.
+func (g *Game) commit(m move) {
+	for _, e := range m {
+		g.cells[e.pos] = e.after
+	}
+	g.undo = append(g.undo, m)
+	g.redo = g.redo[:0]
+	g.hint, g.message = nil, ""
+	g.checkSolved()
+}
+
+// checkSolved stops the clock once the grid matches the solution.
+func (g *Game) checkSolved() {
+	for pos, c := range g.cells {
+		if c.digit != g.solution.Get(pos) {
+			return
+		}
+	}
+	g.solved = true
+	g.finished = time.Since(g.start)
+	g.message = fmt.Sprintf("Solved in %s!", FormatDuration(g.finished))
+}
+
+// peers reports whether two cells share a row, column or region.
+func (g *Game) peers(a, b int) bool {
+	regions := &g.puzzle.Layout().PosToRegion
+	return a/9 == b/9 || a%9 == b%9 || regions[a] == regions[b]
+}
+
+// Conflicts reports, for every cell, whether its digit is repeated in its
+// row, column or region. Like the board's own unit masks, a mask of seen
+// digits is kept per unit; a second mask collects the digits seen twice.
+func (g *Game) Conflicts() [board.CellCount]bool {
+	regions := &g.puzzle.Layout().PosToRegion
+	var seen, dup [27]uint
+	for pos, c := range g.cells {
+		if c.digit == board.EmptyCell {
+			continue
+		}
+		bit := digitMask(c.digit)
+		for _, u := range [3]int{pos / 9, 9 + pos%9, 18 + regions[pos]} {
+			dup[u] |= seen[u] & bit
+			seen[u] |= bit
+		}
+	}
+
+	var conflicts [board.CellCount]bool
+	for pos, c := range g.cells {
+		if c.digit == board.EmptyCell {
+			continue
+		}
+		bit := digitMask(c.digit)
+		conflicts[pos] = (dup[pos/9]|dup[9+pos%9]|dup[18+regions[pos]])&bit != 0
+	}
+	return conflicts
+}
+
+// Hint explains the next step. Wrong entries are pointed out first, since
+// no deduction is sound while they stand. Otherwise the logical solver's
+// easiest step is shown; if none applies, the digit of an empty cell is
+// revealed instead.
+func (g *Game) Hint() {
+	if g.solved {
+		return
+	}
+
+	b := board.New(g.puzzle.Layout())
+	for pos, c := range g.cells {
+		if c.digit == board.EmptyCell {
+			continue
+		}
+		if c.digit != g.solution.Get(pos) {
+			g.cursor = pos
+			g.hint = []int{pos}
+			g.message = fmt.Sprintf("%s is wrong", cellName(pos))
+			return
+		}
+		b.SetForce(pos, c.digit)
+	}
+
+	if step, ok := solver.NewLogical(b).Next(); ok {
+		g.hint = append([]int(nil), step.Cells...)
+		for _, p := range step.Placements {
+			g.hint = append(g.hint, p.Pos)
+		}
+		g.message = step.String()
+		return
+	}
+
+	pos := g.cursor
+	if g.cells[pos].digit != board.EmptyCell {
+		for p, c := range g.cells {
+			if c.digit == board.EmptyCell {
+				pos = p
+				break
+			}
+		}
+	}
+	g.hint = []int{pos}
+	g.message = fmt.Sprintf("No logical step found; %s is %d", cellName(pos), g.solution.Get(pos))
+}
+
+// digitMask returns the mask bit for a digit 1-9.
+func digitMask(digit int) uint {
+	return 1 << (digit - 1)
+}
+
+// cellName returns the 1-based r<row>c<col> name of a position, matching
+// the notation of solver hints.
+func cellName(pos int) string {
+	return fmt.Sprintf("r%dc%d", pos/9+1, pos%9+1)
+}
+
+// FormatDuration formats a duration as m:ss, or h:mm:ss past an hour.
+func FormatDuration(d time.Duration) string {
+	s := int(d.Seconds())
+	if s >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+	}
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
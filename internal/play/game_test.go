@@ -0,0 +1,330 @@
+package play
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rybkr/sudoku/internal/board"
+	"github.com/rybkr/sudoku/internal/generator"
+)
+
+// easyPuzzle is a proper puzzle with a logical solution.
+const easyPuzzle = "..4.9.......7..6..1.....5.2.96.5..2432.84..6...7..635.63.1..4..7....2..3.5...8..6"
+
+// jigsawLayout is the standard layout with r3c3 and r1c4 swapped between
+// the first two regions: r3c3 and r1c5 share a region, r3c3 and r1c1 do not.
+const jigsawLayout = "000011222000111222001111222333444555333444555333444555666777888666777888666777888"
+
+// rc returns the position of row r, column c, both counted from 1.
+func rc(r, c int) int {
+	return board.MakePos(r-1, c-1)
+}
+
+func standardGame(t *testing.T) *Game {
+	t.Helper()
+	puzzle, err := board.NewFromString(easyPuzzle, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := NewGame(puzzle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+// jigsawGame returns a game on jigsawLayout whose only empty cells are row
+// 3, column 3, r1c1 and r1c5. With row 3 and column 3 empty, a digit
+// entered at r3c3 can only conflict with its region.
+func jigsawGame(t *testing.T) *Game {
+	t.Helper()
+	layout, err := board.ParseLayout(jigsawLayout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := generator.DefaultOptions(40)
+	opts.Seed = 1
+	opts.Layout = layout
+	_, solution, err := generator.New(opts).Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	puzzle := solution.Clone()
+	empty := []int{rc(1, 1), rc(1, 5)}
+	for i := range 9 {
+		empty = append(empty, rc(3, i+1), rc(i+1, 3))
+	}
+	for _, pos := range empty {
+		if err := puzzle.Clear(pos); err != nil {
+			t.Fatal(err)
+		}
+	}
+	g, err := NewGame(puzzle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+// enter places digit at pos.
+func enter(g *Game, pos, digit int) {
+	g.cursor = pos
+	g.Enter(digit)
+}
+
+// mark toggles the pencil marks digits at pos.
+func mark(g *Game, pos int, digits ...int) {
+	g.cursor = pos
+	for _, d := range digits {
+		g.ToggleMark(d)
+	}
+}
+
+// marks returns the mask of pencil marks for digits.
+func marks(digits ...int) uint {
+	var m uint
+	for _, d := range digits {
+		m |= digitMask(d)
+	}
+	return m
+}
+
+func TestEnterClearsPeerMarks(t *testing.T) {
+	tests := []struct {
+		name  string
+		game  func(*testing.T) *Game
+		pos   int
+		digit int
+		// before are the pencil marks set first, after what should remain.
+		before, after map[int][]int
+	}{
+		{
+			name: "row, column and box",
+			game: standardGame, pos: rc(1, 1), digit: 5,
+			before: map[int][]int{rc(1, 2): {3, 5}, rc(1, 9): {5}, rc(2, 1): {5}, rc(3, 3): {5}, rc(2, 6): {5}},
+			after:  map[int][]int{rc(1, 2): {3}, rc(1, 9): nil, rc(2, 1): nil, rc(3, 3): nil, rc(2, 6): {5}},
+		},
+		{
+			name: "other digits stay",
+			game: standardGame, pos: rc(1, 1), digit: 5,
+			before: map[int][]int{rc(1, 2): {3, 7}, rc(2, 1): {1, 2, 6}},
+			after:  map[int][]int{rc(1, 2): {3, 7}, rc(2, 1): {1, 2, 6}},
+		},
+		{
+			name: "jigsaw region, not box",
+			game: jigsawGame, pos: rc(3, 3), digit: 5,
+			before: map[int][]int{rc(1, 5): {5, 8}, rc(1, 1): {5}},
+			after:  map[int][]int{rc(1, 5): {8}, rc(1, 1): {5}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := tt.game(t)
+			for pos, digits := range tt.before {
+				mark(g, pos, digits...)
+			}
+			enter(g, tt.pos, tt.digit)
+
+			if got := g.cells[tt.pos]; got != (cell{digit: tt.digit}) {
+				t.Errorf("cell %s is %+v, want only digit %d", cellName(tt.pos), got, tt.digit)
+			}
+			for pos, digits := range tt.after {
+				if got, want := g.cells[pos].marks, marks(digits...); got != want {
+					t.Errorf("marks at %s are %09b, want %09b", cellName(pos), got, want)
+				}
+			}
+			// The placement and the cleared marks are undone together.
+			g.Undo()
+			for pos, digits := range tt.before {
+				if got, want := g.cells[pos].marks, marks(digits...); got != want {
+					t.Errorf("after undo, marks at %s are %09b, want %09b", cellName(pos), got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestUndoRedo(t *testing.T) {
+	// Each case replays the same three moves, the last of which places a
+	// digit and clears two peers' marks, then undoes and redoes. want is the
+	// number of moves whose effect should remain.
+	tests := []struct {
+		name        string
+		undos, redo int
+		want        int
+	}{
+		{"nothing undone", 0, 0, 3},
+		{"undo placement", 1, 0, 2},
+		{"undo all", 3, 0, 0},
+		{"undo past the start", 5, 0, 0},
+		{"redo placement", 1, 1, 3},
+		{"redo part", 3, 2, 2},
+		{"redo past the end", 2, 4, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := standardGame(t)
+			snapshots := [][board.CellCount]cell{g.cells}
+			mark(g, rc(1, 2), 5)
+			snapshots = append(snapshots, g.cells)
+			mark(g, rc(2, 1), 5)
+			snapshots = append(snapshots, g.cells)
+			enter(g, rc(1, 1), 5)
+			snapshots = append(snapshots, g.cells)
+
+			for range tt.undos {
+				g.Undo()
+			}
+			for range tt.redo {
+				g.Redo()
+			}
+			if g.cells != snapshots[tt.want] {
+				for pos := range g.cells {
+					if g.cells[pos] != snapshots[tt.want][pos] {
+						t.Errorf("%s is %+v, want %+v", cellName(pos), g.cells[pos], snapshots[tt.want][pos])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestUndoMovesCursor(t *testing.T) {
+	g := standardGame(t)
+	mark(g, rc(1, 2), 5)
+	enter(g, rc(1, 1), 5)
+	g.cursor = rc(9, 9)
+
+	g.Undo()
+	if g.cursor != rc(1, 1) {
+		t.Errorf("undo left the cursor at %s, want r1c1", cellName(g.cursor))
+	}
+	g.cursor = rc(9, 9)
+	g.Redo()
+	if g.cursor != rc(1, 1) {
+		t.Errorf("redo left the cursor at %s, want r1c1", cellName(g.cursor))
+	}
+}
+
+func TestNewMoveDropsRedo(t *testing.T) {
+	g := standardGame(t)
+	enter(g, rc(1, 1), 5)
+	g.Undo()
+	enter(g, rc(1, 2), 3)
+	g.Redo()
+	if d := g.cells[rc(1, 1)].digit; d != board.EmptyCell {
+		t.Errorf("redo after a new move placed %d at r1c1", d)
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries [][2]int // position, digit
+		want    []int
+	}{
+		{"none", nil, nil},
+		{"row", [][2]int{{rc(1, 1), 4}}, []int{rc(1, 1), rc(1, 3)}},
+		{"column", [][2]int{{rc(3, 5), 9}}, []int{rc(1, 5), rc(3, 5)}},
+		{"box", [][2]int{{rc(2, 1), 4}}, []int{rc(1, 3), rc(2, 1)}},
+		{"two entries", [][2]int{{rc(1, 1), 8}, {rc(2, 2), 8}}, []int{rc(1, 1), rc(2, 2)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := standardGame(t)
+			for _, e := range tt.entries {
+				enter(g, e[0], e[1])
+			}
+			if got := conflicting(g); !equalInts(got, tt.want) {
+				t.Errorf("conflicts at %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestConflictsJigsaw checks that on a jigsaw board the regions, not the
+// boxes, are searched for repeated digits.
+func TestConflictsJigsaw(t *testing.T) {
+	t.Run("same region", func(t *testing.T) {
+		g := jigsawGame(t)
+		digit := g.solution.Get(rc(1, 5))
+		enter(g, rc(1, 5), digit)
+		enter(g, rc(3, 3), digit)
+		if got, want := conflicting(g), []int{rc(1, 5), rc(3, 3)}; !equalInts(got, want) {
+			t.Errorf("conflicts at %v, want %v", got, want)
+		}
+	})
+	t.Run("same box only", func(t *testing.T) {
+		g := jigsawGame(t)
+		digit := g.solution.Get(rc(1, 1))
+		enter(g, rc(1, 1), digit)
+		enter(g, rc(3, 3), digit)
+		if g.Conflicts()[rc(1, 1)] {
+			t.Errorf("%d at r1c1 conflicts with r3c3, outside its region", digit)
+		}
+	})
+}
+
+// conflicting returns the positions Conflicts reports, in order.
+func conflicting(g *Game) []int {
+	var got []int
+	for pos, c := range g.Conflicts() {
+		if c {
+			got = append(got, pos)
+		}
+	}
+	return got
+}
+
+func TestHint(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries [][2]int // position, digit; 0 stands for the solution's digit
+		wrong   int      // position reported as wrong, or -1
+	}{
+		{"no entries", nil, -1},
+		{"correct entry", [][2]int{{rc(1, 1), 0}}, -1},
+		{"wrong entry", [][2]int{{rc(2, 2), -1}}, rc(2, 2)},
+		{"first wrong entry", [][2]int{{rc(9, 1), -1}, {rc(2, 2), -1}}, rc(2, 2)},
+		{"wrong among correct", [][2]int{{rc(1, 1), 0}, {rc(5, 3), -1}}, rc(5, 3)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := standardGame(t)
+			for _, e := range tt.entries {
+				digit := g.solution.Get(e[0])
+				if e[1] < 0 {
+					digit = digit%9 + 1
+				}
+				enter(g, e[0], digit)
+			}
+			g.cursor = rc(9, 9)
+			g.Hint()
+
+			if tt.wrong >= 0 {
+				if g.cursor != tt.wrong || !equalInts(g.hint, []int{tt.wrong}) {
+					t.Errorf("cursor %s, hint %v; want both at %s", cellName(g.cursor), g.hint, cellName(tt.wrong))
+				}
+				if want := cellName(tt.wrong) + " is wrong"; g.message != want {
+					t.Errorf("message %q, want %q", g.message, want)
+				}
+				return
+			}
+			if strings.HasSuffix(g.message, "is wrong") || len(g.hint) == 0 {
+				t.Errorf("hint %v, %q; want a logical step", g.hint, g.message)
+			}
+		})
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
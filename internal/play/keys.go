@@ -0,0 +1,129 @@
+package play
+
+import "strings"
+
+// action is something the player asked for with a key press.
+type action int
+
+const (
+	actNone action = iota
+	actUp
+	actDown
+	actLeft
+	actRight
+	actDigit // place a digit, or mark it in pencil mode
+	actMark  // toggle a pencil mark regardless of mode
+	actClear
+	actPencil
+	actUndo
+	actRedo
+	actHint
+	actQuit
+)
+
+// key is a decoded key press.
+type key struct {
+	action action
+	digit  int // for actDigit and actMark
+}
+
+// shiftedDigits are the characters typed by shift+1 to shift+9 on a US
+// keyboard, used as a shortcut for pencil marks.
+const shiftedDigits = "!@#$%^&*("
+
+// parseKeys decodes the bytes of one terminal read. Arrow keys arrive as
+// escape sequences (ESC [ A, or ESC O A in application mode); a lone ESC or
+// an unknown sequence is ignored.
+func parseKeys(buf []byte) []key {
+	var keys []key
+	for i := 0; i < len(buf); i++ {
+		b := buf[i]
+		if b == 0x1b {
+			if i+2 < len(buf) && (buf[i+1] == '[' || buf[i+1] == 'O') {
+				switch buf[i+2] {
+				case 'A':
+					keys = append(keys, key{action: actUp})
+				case 'B':
+					keys = append(keys, key{action: actDown})
+				case 'C':
+					keys = append(keys, key{action: actRight})
+				case 'D':
+					keys = append(keys, key{action: actLeft})
+				case '3':
+					// Delete is ESC [ 3 ~.
+					keys = append(keys, key{action: actClear})
+					if i+3 < len(buf) && buf[i+3] == '~' {
+						i++
+					}
+				}
+				i += 2
+			}
+			continue
+		}
+
+		if b >= '1' && b <= '9' {
+			keys = append(keys, key{action: actDigit, digit: int(b - '0')})
+			continue
+		}
+		if d := strings.IndexByte(shiftedDigits, b); d >= 0 {
+			keys = append(keys, key{action: actMark, digit: d + 1})
+			continue
+		}
+
+		switch b {
+		case 'k', 'K':
+			keys = append(keys, key{action: actUp})
+		case 'j', 'J':
+			keys = append(keys, key{action: actDown})
+		case 'h', 'H':
+			keys = append(keys, key{action: actLeft})
+		case 'l', 'L':
+			keys = append(keys, key{action: actRight})
+		case '0', '.', ' ', 'x', 0x7f, 0x08:
+			keys = append(keys, key{action: actClear})
+		case 'p', 'P':
+			keys = append(keys, key{action: actPencil})
+		case 'u', 'U', 0x1a: // Ctrl-Z
+			keys = append(keys, key{action: actUndo})
+		case 'r', 'R', 0x19: // Ctrl-Y
+			keys = append(keys, key{action: actRedo})
+		case '?':
+			keys = append(keys, key{action: actHint})
+		case 'q', 'Q', 0x03, 0x04: // Ctrl-C, Ctrl-D
+			keys = append(keys, key{action: actQuit})
+		}
+	}
+	return keys
+}
+
+// handle applies a key press to the game. It reports false when the player
+// asked to quit.
+func (g *Game) handle(k key) bool {
+	switch k.action {
+	case actUp:
+		g.Move(-1, 0)
+	case actDown:
+		g.Move(1, 0)
+	case actLeft:
+		g.Move(0, -1)
+	case actRight:
+		g.Move(0, 1)
+	case actDigit:
+		g.Enter(k.digit)
+	case actMark:
+		g.ToggleMark(k.digit)
+	case actClear:
+		g.Clear()
+	case actPencil:
+		g.TogglePencil()
+	case actUndo:
+		g.Undo()
+	case actRedo:
+		g.Redo()
+	case actHint:
+		g.Hint()
+	case actQuit:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,92 @@
+// Package play implements an interactive full-screen Sudoku game for ANSI
+// terminals. It depends only on the standard library and the stty utility.
+package play
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+// ErrNoTerminal is returned when the game cannot take over the terminal,
+// for example because stdin is a pipe.
+var ErrNoTerminal = errors.New("play needs an interactive terminal")
+
+// Alternate screen and cursor visibility sequences.
+const (
+	ansiAltScreen  = "\x1b[?1049h"
+	ansiMainScreen = "\x1b[?1049l"
+	ansiHideCursor = "\x1b[?25l"
+	ansiShowCursor = "\x1b[?25h"
+)
+
+// Play runs the game full screen on the process's terminal until the player
+// quits or ctx is done. The terminal is restored before Play returns.
+func Play(ctx context.Context, g *Game) error {
+	restore, err := makeRaw(os.Stdin)
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	io.WriteString(os.Stdout, ansiAltScreen+ansiHideCursor)
+	defer io.WriteString(os.Stdout, ansiShowCursor+ansiMainScreen)
+
+	return Run(ctx, g, os.Stdin, os.Stdout)
+}
+
+// Run drives a game from key presses read from in, redrawing it on out after
+// every key and once a second for the timer. It returns nil when the player
+// quits, ctx is done or in reaches EOF. The terminal must already be raw.
+func Run(ctx context.Context, g *Game, in io.Reader, out io.Writer) error {
+	done := make(chan struct{})
+	defer close(done)
+
+	keys := make(chan []key)
+	errc := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 64)
+		for {
+			n, err := in.Read(buf)
+			if n > 0 {
+				select {
+				case keys <- parseKeys(buf[:n]):
+				case <-done:
+					return
+				}
+			}
+			if err != nil {
+				errc <- err
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		if _, err := io.WriteString(out, g.Render()); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errc:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		case <-ticker.C:
+		case ks := <-keys:
+			for _, k := range ks {
+				if !g.handle(k) {
+					return nil
+				}
+			}
+		}
+	}
+}
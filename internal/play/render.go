@@ -0,0 +1,246 @@
+package play
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/rybkr/sudoku/internal/board"
+)
+
+// ANSI escape sequences used by the renderer.
+const (
+	ansiReset     = "\x1b[0m"
+	ansiBold      = "\x1b[1m"
+	ansiDim       = "\x1b[2m"
+	ansiUnderline = "\x1b[4m"
+	ansiReverse   = "\x1b[7m"
+	ansiRed       = "\x1b[31m"
+	ansiCyan      = "\x1b[36m"
+	ansiHintBG    = "\x1b[42m"
+
+	ansiHome      = "\x1b[H"
+	ansiClearLine = "\x1b[K"
+	ansiClearDown = "\x1b[J"
+)
+
+// Line weights for the grid: cell boundaries inside a region are light,
+// region boundaries and the outer frame heavy.
+const (
+	lineNone = iota
+	lineLight
+	lineHeavy
+)
+
+// junctions maps the weights of the up, right, down and left arms meeting at
+// a grid corner to the box-drawing rune that joins them.
+var junctions = map[[4]int]rune{
+	{0, 2, 2, 0}: '┏', {0, 0, 2, 2}: '┓', {2, 2, 0, 0}: '┗', {2, 0, 0, 2}: '┛',
+	{0, 2, 1, 2}: '┯', {0, 2, 2, 2}: '┳', {1, 2, 0, 2}: '┷', {2, 2, 0, 2}: '┻',
+	{2, 1, 2, 0}: '┠', {2, 2, 2, 0}: '┣', {2, 0, 2, 1}: '┨', {2, 0, 2, 2}: '┫',
+	{1, 1, 1, 1}: '┼', {1, 1, 1, 2}: '┽', {1, 2, 1, 1}: '┾', {1, 2, 1, 2}: '┿',
+	{2, 1, 1, 1}: '╀', {1, 1, 2, 1}: '╁', {2, 1, 2, 1}: '╂', {2, 1, 1, 2}: '╃',
+	{2, 2, 1, 1}: '╄', {1, 1, 2, 2}: '╅', {1, 2, 2, 1}: '╆', {2, 2, 1, 2}: '╇',
+	{1, 2, 2, 2}: '╈', {2, 1, 2, 2}: '╉', {2, 2, 2, 1}: '╊', {2, 2, 2, 2}: '╋',
+}
+
+// horizontal returns the weight of the edge above cell (row, col); row 9 is
+// the bottom frame.
+func horizontal(regions *[board.CellCount]int, row, col int) int {
+	if row == 0 || row == 9 {
+		return lineHeavy
+	}
+	if regions[board.MakePos(row-1, col)] != regions[board.MakePos(row, col)] {
+		return lineHeavy
+	}
+	return lineLight
+}
+
+// vertical returns the weight of the edge left of cell (row, col); col 9 is
+// the right frame.
+func vertical(regions *[board.CellCount]int, row, col int) int {
+	if col == 0 || col == 9 {
+		return lineHeavy
+	}
+	if regions[board.MakePos(row, col-1)] != regions[board.MakePos(row, col)] {
+		return lineHeavy
+	}
+	return lineLight
+}
+
+// junction returns the rune drawn at grid corner (row, col), 0 <= row, col <= 9.
+func junction(regions *[board.CellCount]int, row, col int) string {
+	var arms [4]int
+	if row > 0 {
+		arms[0] = vertical(regions, row-1, col)
+	}
+	if col < 9 {
+		arms[1] = horizontal(regions, row, col)
+	}
+	if row < 9 {
+		arms[2] = vertical(regions, row, col)
+	}
+	if col > 0 {
+		arms[3] = horizontal(regions, row, col-1)
+	}
+	return styleLine(string(junctions[arms]), max(arms[0], arms[1], arms[2], arms[3]))
+}
+
+// styleLine dims light lines so region boundaries stand out.
+func styleLine(s string, weight int) string {
+	if weight == lineLight {
+		return ansiDim + s + ansiReset
+	}
+	return s
+}
+
+// grid draws the board, one string per terminal line. Region boundaries
+// come from the layout, so jigsaw regions are outlined like standard boxes.
+func (g *Game) grid() []string {
+	regions := &g.puzzle.Layout().PosToRegion
+	conflicts := g.Conflicts()
+	cursorDigit := g.cells[g.cursor].digit
+
+	var lines []string
+	for row := 0; row <= 9; row++ {
+		var sb strings.Builder
+		for col := 0; col <= 9; col++ {
+			sb.WriteString(junction(regions, row, col))
+			if col < 9 {
+				w := horizontal(regions, row, col)
+				line := "───"
+				if w == lineHeavy {
+					line = "━━━"
+				}
+				sb.WriteString(styleLine(line, w))
+			}
+		}
+		lines = append(lines, sb.String())
+		if row == 9 {
+			break
+		}
+
+		sb.Reset()
+		for col := 0; col <= 9; col++ {
+			w := vertical(regions, row, col)
+			line := "│"
+			if w == lineHeavy {
+				line = "┃"
+			}
+			sb.WriteString(styleLine(line, w))
+			if col < 9 {
+				pos := board.MakePos(row, col)
+				sb.WriteString(g.cellText(pos, conflicts[pos], cursorDigit))
+			}
+		}
+		lines = append(lines, sb.String())
+	}
+	return lines
+}
+
+// cellText renders the three columns of one cell with its styling.
+func (g *Game) cellText(pos int, conflict bool, cursorDigit int) string {
+	c := g.cells[pos]
+
+	var style, text string
+	switch {
+	case c.digit != board.EmptyCell:
+		text = fmt.Sprintf(" %d ", c.digit)
+		switch {
+		case conflict:
+			style = ansiBold + ansiRed
+		case g.given(pos):
+			style = ansiBold
+		default:
+			style = ansiCyan
+		}
+		if c.digit == cursorDigit && pos != g.cursor {
+			style += ansiUnderline
+		}
+	case c.marks != 0:
+		// Three columns fit up to three marks; more are abbreviated.
+		var digits []byte
+		for d := 1; d <= 9; d++ {
+			if c.marks&digitMask(d) != 0 {
+				digits = append(digits, byte('0'+d))
+			}
+		}
+		if len(digits) > 3 {
+			digits = append(digits[:2], '+')
+		}
+		text = fmt.Sprintf("%-3s", digits)
+		style = ansiDim
+	default:
+		text = "   "
+	}
+
+	if slices.Contains(g.hint, pos) {
+		style += ansiHintBG
+	}
+	if pos == g.cursor {
+		style += ansiReverse
+	}
+	if style == "" {
+		return text
+	}
+	return style + text + ansiReset
+}
+
+// panel returns the status lines shown beside the grid.
+func (g *Game) panel() []string {
+	mode := "digits"
+	if g.pencil {
+		mode = "pencil marks"
+	}
+	kind := "Sudoku"
+	if g.puzzle.Layout().Type == "jigsaw" {
+		kind = "Jigsaw Sudoku"
+	}
+
+	var marks []string
+	for d := 1; d <= 9; d++ {
+		if g.cells[g.cursor].marks&digitMask(d) != 0 {
+			marks = append(marks, fmt.Sprint(d))
+		}
+	}
+
+	return []string{
+		ansiBold + kind + ansiReset,
+		"",
+		fmt.Sprintf("Time   %s", FormatDuration(g.Elapsed())),
+		fmt.Sprintf("Left   %d", g.Remaining()),
+		fmt.Sprintf("Mode   %s", mode),
+		fmt.Sprintf("Cell   %s", cellName(g.cursor)),
+		fmt.Sprintf("Marks  %s", strings.Join(marks, " ")),
+		"",
+		ansiDim + "arrows/hjkl  move" + ansiReset,
+		ansiDim + "1-9          digit (mark in pencil mode)" + ansiReset,
+		ansiDim + "shift+1-9    pencil mark" + ansiReset,
+		ansiDim + "0 . del      clear" + ansiReset,
+		ansiDim + "p            pencil mode" + ansiReset,
+		ansiDim + "u / r        undo / redo" + ansiReset,
+		ansiDim + "?            hint" + ansiReset,
+		ansiDim + "q            quit" + ansiReset,
+	}
+}
+
+// Render returns a full frame: the grid with the panel beside it and the
+// current message below. Lines end in CR LF since the terminal is raw.
+func (g *Game) Render() string {
+	grid, panel := g.grid(), g.panel()
+
+	var sb strings.Builder
+	sb.WriteString(ansiHome)
+	for i, line := range grid {
+		sb.WriteString(line)
+		if i < len(panel) {
+			sb.WriteString("   ")
+			sb.WriteString(panel[i])
+		}
+		sb.WriteString(ansiClearLine + "\r\n")
+	}
+	sb.WriteString(ansiClearLine + "\r\n")
+	sb.WriteString(g.message)
+	sb.WriteString(ansiClearLine + "\r\n" + ansiClearDown)
+	return sb.String()
+}
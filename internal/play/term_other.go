@@ -0,0 +1,14 @@
+//go:build !unix
+
+package play
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// makeRaw is only implemented for Unix terminals.
+func makeRaw(f *os.File) (restore func(), err error) {
+	return nil, fmt.Errorf("%w: raw mode is not supported on %s", ErrNoTerminal, runtime.GOOS)
+}
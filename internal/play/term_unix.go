@@ -0,0 +1,34 @@
+//go:build unix
+
+package play
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// makeRaw puts the terminal on f into raw mode with echo off, using stty so
+// that no terminal library is needed. The returned function restores the
+// previous settings.
+func makeRaw(f *os.File) (restore func(), err error) {
+	saved, err := stty(f, "-g")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoTerminal, err)
+	}
+	if _, err := stty(f, "raw", "-echo"); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoTerminal, err)
+	}
+	return func() {
+		stty(f, strings.TrimSpace(saved))
+	}, nil
+}
+
+// stty runs stty with f as its terminal and returns its output.
+func stty(f *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = f
+	out, err := cmd.Output()
+	return string(out), err
+}
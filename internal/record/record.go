@@ -0,0 +1,177 @@
+// Package record defines the JSON schema puzzles are exchanged in.
+//
+// A record carries a puzzle together with everything needed to use it on its
+// own: the solution, clue count, difficulty, the seed and number that
+// regenerate it, the board type and, for jigsaw puzzles, the region map.
+// Records are written either as a JSON array or as JSON Lines (one record per
+// line), and Read accepts both, as well as a single object.
+package record
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/rybkr/sudoku/internal/board"
+	"github.com/rybkr/sudoku/internal/solver"
+)
+
+var (
+	ErrMissingPuzzle = errors.New("record has no puzzle")
+	ErrMissingLayout = errors.New("jigsaw record has no layout")
+)
+
+// Difficulty is a solver.Rating in a record.
+type Difficulty struct {
+	Score    float64 `json:"score"`
+	Category string  `json:"category"`
+}
+
+// Record is one puzzle. Boards are 81-character strings in row-major order
+// with '.' for empty cells; Layout holds 81 region indices 0-8 and is only
+// written for jigsaw puzzles.
+type Record struct {
+	// Number is the puzzle's position in a generated run, from 1.
+	Number int `json:"number,omitempty"`
+	// Date is the day of a daily puzzle, as YYYY-MM-DD.
+	Date       string      `json:"date,omitempty"`
+	Puzzle     string      `json:"puzzle"`
+	Solution   string      `json:"solution,omitempty"`
+	Clues      int         `json:"clues"`
+	Difficulty *Difficulty `json:"difficulty,omitempty"`
+	// Seed is the seed of the run that generated the puzzle: 'sudoku gen
+	// --seed Seed' with the same options and at least Number puzzles
+	// generates it again as puzzle Number. Daily puzzles have none; their
+	// date, category and type regenerate them with 'sudoku daily'.
+	Seed   int64  `json:"seed,omitempty"`
+	Type   string `json:"type"`
+	Layout string `json:"layout,omitempty"`
+}
+
+// New describes a puzzle, its solution and its rating. solution may be nil.
+func New(puzzle, solution *board.Board, rating solver.Rating) Record {
+	r := Record{
+		Puzzle:     puzzle.String(),
+		Clues:      puzzle.ClueCount(),
+		Difficulty: &Difficulty{Score: rating.Score, Category: rating.Category.String()},
+		Type:       puzzle.Layout().Type,
+	}
+	if solution != nil {
+		r.Solution = solution.String()
+	}
+	if r.Type == "jigsaw" {
+		r.Layout = puzzle.Layout().String()
+	}
+	return r
+}
+
+// Board parses the record's puzzle with its layout. A layout is used even
+// when Type is empty, so hand-written records may leave Type out.
+func (r Record) Board() (*board.Board, error) {
+	if r.Puzzle == "" {
+		return nil, ErrMissingPuzzle
+	}
+	switch r.Type {
+	case "", "standard", "jigsaw":
+	default:
+		return nil, fmt.Errorf("unknown board type %q: must be standard or jigsaw", r.Type)
+	}
+
+	var layout *board.Layout
+	switch {
+	case r.Layout != "":
+		var err error
+		if layout, err = board.ParseLayout(r.Layout); err != nil {
+			return nil, err
+		}
+	case r.Type == "jigsaw":
+		return nil, ErrMissingLayout
+	}
+	return board.NewFromString(r.Puzzle, layout)
+}
+
+// IsJSON reports whether data looks like records rather than plain puzzle
+// lines, judging by its first non-blank character.
+func IsJSON(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && (data[0] == '{' || data[0] == '[')
+}
+
+// Read decodes records from a JSON array, a single JSON object or a stream
+// of objects such as JSON Lines. Errors name the record they occur in.
+func Read(r io.Reader) ([]Record, error) {
+	br := bufio.NewReader(r)
+	dec := json.NewDecoder(br)
+
+	// An array is read in one go; anything else is a stream of objects.
+	if first, err := firstByte(br); err == nil && first == '[' {
+		var records []Record
+		if err := dec.Decode(&records); err != nil {
+			return nil, fmt.Errorf("invalid record array: %w", err)
+		}
+		return records, check(records)
+	}
+
+	var records []Record
+	for {
+		var rec Record
+		err := dec.Decode(&rec)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", len(records)+1, err)
+		}
+		records = append(records, rec)
+	}
+	return records, check(records)
+}
+
+// firstByte peeks at the first non-whitespace byte of br.
+func firstByte(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		return b, br.UnreadByte()
+	}
+}
+
+// check reports the first record without a puzzle.
+func check(records []Record) error {
+	for i, rec := range records {
+		if rec.Puzzle == "" {
+			return fmt.Errorf("record %d: %w", i+1, ErrMissingPuzzle)
+		}
+	}
+	return nil
+}
+
+// WriteJSON writes records as an indented JSON array.
+func WriteJSON(w io.Writer, records []Record) error {
+	if records == nil {
+		records = []Record{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(records)
+}
+
+// WriteJSONL writes each record as one line of JSON.
+func WriteJSONL(w io.Writer, records ...Record) error {
+	enc := json.NewEncoder(w)
+	for _, rec := range records {
+		if err := enc.Encode(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
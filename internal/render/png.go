@@ -0,0 +1,234 @@
+package render
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/rybkr/sudoku/internal/board"
+)
+
+// PNG writes b as a PNG image. Text is set in the embedded bitmap font,
+// scaled to the cell size, so the output is the same on every machine.
+// Style colours must be written as #rgb or #rrggbb. Style.Font is ignored,
+// and a weight of bold or 600 and above draws heavier digits.
+func PNG(w io.Writer, b *board.Board, opts Options) error {
+	if opts.Description == "" {
+		return png.Encode(w, Image(b, opts))
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, Image(b, opts)); err != nil {
+		return err
+	}
+	// image/png writes no text chunks, so splice one in after IHDR, which
+	// is always the first chunk: 8 bytes of signature, then 25 of header.
+	const ihdrEnd = 8 + 25
+	data := buf.Bytes()
+	if _, err := w.Write(data[:ihdrEnd]); err != nil {
+		return err
+	}
+	if _, err := w.Write(textChunk("Description", opts.Description)); err != nil {
+		return err
+	}
+	_, err := w.Write(data[ihdrEnd:])
+	return err
+}
+
+// textChunk returns a PNG tEXt chunk holding text under keyword. Both are
+// meant to be Latin-1; other bytes are written as they are.
+func textChunk(keyword, text string) []byte {
+	body := append([]byte("tEXt"+keyword+"\x00"), text...)
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(body)-4))
+	chunk = append(chunk, body...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(body))
+}
+
+// Image draws b as PNG does and returns the image.
+func Image(b *board.Board, opts Options) *image.RGBA {
+	style := opts.Style.withDefaults()
+	cell := opts.cellSize()
+	thin := max(1, cell/48)
+	thick := max(2, cell/16)
+	pad := thick
+	size := 9*cell + 2*pad
+
+	// The caption, if any, sits above the grid with a margin of a quarter
+	// cell round it. Captions too long for the image are set smaller.
+	titleScale, subScale := max(1, cell/16), max(1, cell/24)
+	for titleScale > 1 && textWidth(opts.Title, titleScale) > size-2*pad {
+		titleScale--
+	}
+	for subScale > 1 && textWidth(opts.Subtitle, subScale) > size-2*pad {
+		subScale--
+	}
+	header := 0
+	if opts.Title != "" || opts.Subtitle != "" {
+		header = cell / 4
+		if opts.Title != "" {
+			header += glyphHeight*titleScale + cell/8
+		}
+		if opts.Subtitle != "" {
+			header += glyphHeight*subScale + cell/8
+		}
+		header += cell / 8
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, size, size+header))
+	fill(img, img.Bounds(), parseColor(style.Background, DefaultStyle.Background))
+
+	y := cell / 4
+	if opts.Title != "" {
+		drawText(img, size/2, y, titleScale, opts.Title, parseColor(style.Given, DefaultStyle.Given), false)
+		y += glyphHeight*titleScale + cell/8
+	}
+	if opts.Subtitle != "" {
+		drawText(img, size/2, y, subScale, opts.Subtitle, parseColor(style.Candidate, DefaultStyle.Candidate), false)
+	}
+
+	x0, y0 := pad, pad+header
+	layout := b.Layout()
+	cellRect := func(pos int) image.Rectangle {
+		x, y := x0+pos%9*cell, y0+pos/9*cell
+		return image.Rect(x, y, x+cell, y+cell)
+	}
+
+	if opts.Shade {
+		for pos := range board.CellCount {
+			fill(img, cellRect(pos), parseColor(pastels[layout.PosToRegion[pos]%len(pastels)], "#ffffff"))
+		}
+	}
+
+	line := parseColor(style.Line, DefaultStyle.Line)
+	for i := 1; i < 9; i++ {
+		off := i*cell - thin/2
+		fill(img, image.Rect(x0+off, y0, x0+off+thin, y0+9*cell), line)
+		fill(img, image.Rect(x0, y0+off, x0+9*cell, y0+off+thin), line)
+	}
+
+	// Thick sides are centred on the cell edge and overshoot its ends by
+	// half their width, so they meet squarely at corners. A boundary
+	// between two cells is marked on both, so the top and left sides of
+	// every cell cover the inside and the last row and column add the outer
+	// edge.
+	border := parseColor(style.Border, DefaultStyle.Border)
+	h := func(x, y int) {
+		fill(img, image.Rect(x-thick/2, y-thick/2, x+cell+thick-thick/2, y+thick-thick/2), border)
+	}
+	v := func(x, y int) {
+		fill(img, image.Rect(x-thick/2, y-thick/2, x+thick-thick/2, y+cell+thick-thick/2), border)
+	}
+	for pos := range board.CellCount {
+		r := cellRect(pos)
+		borders := layout.Borders(pos)
+		if borders&board.BorderTop != 0 {
+			h(r.Min.X, r.Min.Y)
+		}
+		if borders&board.BorderLeft != 0 {
+			v(r.Min.X, r.Min.Y)
+		}
+		if pos/9 == 8 {
+			h(r.Min.X, r.Max.Y)
+		}
+		if pos%9 == 8 {
+			v(r.Max.X, r.Min.Y)
+		}
+	}
+
+	given := parseColor(style.Given, DefaultStyle.Given)
+	filled := parseColor(style.Filled, DefaultStyle.Filled)
+	candidate := parseColor(style.Candidate, DefaultStyle.Candidate)
+	digitScale := max(1, (cell*55/100+glyphHeight/2)/glyphHeight)
+	markScale := max(1, cell/32)
+	for pos := range board.CellCount {
+		r := cellRect(pos)
+		cx, cy := (r.Min.X+r.Max.X)/2, (r.Min.Y+r.Max.Y)/2
+
+		val, c, bold := b.Get(pos), given, isBold(style.GivenWeight)
+		if val == board.EmptyCell && opts.Solution != nil {
+			val, c, bold = opts.Solution.Get(pos), filled, isBold(style.FilledWeight)
+		}
+		if val != board.EmptyCell {
+			drawText(img, cx, cy-glyphHeight*digitScale/2, digitScale, strconv.Itoa(val), c, bold)
+			continue
+		}
+
+		// Candidates sit in a 3×3 grid within the cell, 1 at top left.
+		if opts.Candidates == nil {
+			continue
+		}
+		for digit := 1; digit <= 9; digit++ {
+			if opts.Candidates[pos]&(1<<(digit-1)) == 0 {
+				continue
+			}
+			mx := cx + ((digit-1)%3-1)*cell*3/10
+			my := cy + ((digit-1)/3-1)*cell*3/10
+			drawText(img, mx, my-glyphHeight*markScale/2, markScale, strconv.Itoa(digit), candidate, false)
+		}
+	}
+	return img
+}
+
+// fill paints r in c.
+func fill(img draw.Image, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+// drawText draws s centred on x with its top at y, each font pixel scale
+// pixels square and one font pixel between glyphs. Bold text widens every
+// font pixel by a third.
+func drawText(img draw.Image, x, y, scale int, s string, c color.Color, bold bool) {
+	left := x - textWidth(s, scale)/2
+	shift := 0
+	if bold {
+		shift = max(1, scale/3)
+		left -= shift / 2
+	}
+	for i, r := range []rune(s) {
+		g := glyph(r)
+		gx := left + i*(glyphWidth+1)*scale
+		for col, bits := range g {
+			for row := range glyphHeight {
+				if bits>>row&1 == 0 {
+					continue
+				}
+				px, py := gx+col*scale, y+row*scale
+				fill(img, image.Rect(px, py, px+scale+shift, py+scale), c)
+			}
+		}
+	}
+}
+
+// textWidth returns the width of s drawn at scale.
+func textWidth(s string, scale int) int {
+	return max(0, len([]rune(s))*(glyphWidth+1)*scale-scale)
+}
+
+// isBold reports whether a CSS font weight is bold.
+func isBold(weight string) bool {
+	if weight == "bold" || weight == "bolder" {
+		return true
+	}
+	n, err := strconv.Atoi(weight)
+	return err == nil && n >= 600
+}
+
+// parseColor parses a #rgb or #rrggbb colour, falling back to def, which
+// must parse.
+func parseColor(s, def string) color.RGBA {
+	hex := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if len(hex) != 6 || err != nil {
+		return parseColor(def, def)
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}
+}
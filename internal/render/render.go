@@ -0,0 +1,109 @@
+// Package render draws boards as standalone images for web pages,
+// newsletters and the like.
+//
+// Every renderer outlines regions the same way: each side of a cell that
+// board.Layout.Borders reports as a region boundary is drawn thick, which
+// gives the 3×3 boxes of a standard board and the irregular regions of a
+// jigsaw alike.
+package render
+
+import "github.com/rybkr/sudoku/internal/board"
+
+// DefaultCellSize is the width of a cell when Options.CellSize is zero.
+const DefaultCellSize = 48
+
+// Options configures a rendering.
+type Options struct {
+	// CellSize is the width of a cell in pixels (default DefaultCellSize).
+	CellSize int
+	// Solution, when set, fills the empty cells of the board with its
+	// digits, drawn in the Filled style so they stand out from the givens.
+	Solution *board.Board
+	// Candidates holds pencil marks by position, one bit per digit with
+	// bit 0 for 1, as from board.Board.GetCandidatesMask. Marks are drawn
+	// only in cells that hold no digit. Nil draws none.
+	Candidates []uint
+	// Shade fills each region with a pastel colour of its own.
+	Shade bool
+	// Title and Subtitle, when set, are printed above the grid, e.g. the
+	// puzzle number and its difficulty.
+	Title, Subtitle string
+	// Description, when set, is stored in the image without being drawn:
+	// as the desc element of an SVG and a Description text chunk of a PNG.
+	Description string
+	// Style sets colours and fonts; zero fields take DefaultStyle's.
+	Style Style
+}
+
+// pastels shade the nine regions when Options.Shade is set; regions are
+// numbered from 0, so neighbours always differ.
+var pastels = [9]string{
+	"#ffd6d6", "#ffe8c7", "#fff6bf", "#dcf5d0", "#d6f2ea",
+	"#cdeffa", "#d8defa", "#ead6f7", "#f9d7ec",
+}
+
+// Style sets the colours, as CSS colour values, and fonts of a rendering.
+type Style struct {
+	Background string
+	Line       string // thin lines between cells
+	Border     string // thick region boundaries
+	Given      string // digits of the board
+	Filled     string // digits taken from Options.Solution
+	Candidate  string // pencil marks and the subtitle
+	Font       string // a CSS font-family list; PNG uses its own font
+	// GivenWeight and FilledWeight are CSS font weights, e.g. "bold".
+	GivenWeight  string
+	FilledWeight string
+}
+
+// DefaultStyle draws black givens and blue filled digits on white.
+var DefaultStyle = Style{
+	Background:   "#ffffff",
+	Line:         "#999999",
+	Border:       "#000000",
+	Given:        "#000000",
+	Filled:       "#2563eb",
+	Candidate:    "#666666",
+	Font:         "Helvetica, Arial, sans-serif",
+	GivenWeight:  "bold",
+	FilledWeight: "normal",
+}
+
+// withDefaults fills the zero fields of s from DefaultStyle.
+func (s Style) withDefaults() Style {
+	fill := func(v *string, def string) {
+		if *v == "" {
+			*v = def
+		}
+	}
+	fill(&s.Background, DefaultStyle.Background)
+	fill(&s.Line, DefaultStyle.Line)
+	fill(&s.Border, DefaultStyle.Border)
+	fill(&s.Given, DefaultStyle.Given)
+	fill(&s.Filled, DefaultStyle.Filled)
+	fill(&s.Candidate, DefaultStyle.Candidate)
+	fill(&s.Font, DefaultStyle.Font)
+	fill(&s.GivenWeight, DefaultStyle.GivenWeight)
+	fill(&s.FilledWeight, DefaultStyle.FilledWeight)
+	return s
+}
+
+// cellSize returns the cell width to draw with.
+func (o Options) cellSize() int {
+	if o.CellSize > 0 {
+		return o.CellSize
+	}
+	return DefaultCellSize
+}
+
+// CandidateMasks returns the candidates of every empty cell of b, for
+// Options.Candidates.
+func CandidateMasks(b *board.Board) []uint {
+	masks := make([]uint, board.CellCount)
+	for pos := range board.CellCount {
+		if b.Get(pos) == board.EmptyCell {
+			masks[pos] = b.GetCandidatesMask(pos)
+		}
+	}
+	return masks
+}
@@ -0,0 +1,168 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/rybkr/sudoku/internal/board"
+)
+
+// SVG writes b as a standalone SVG document. Elements carry the classes
+// title, subtitle, grid-line, region-border, given, filled and candidate,
+// and the style sheet in the document sets their look from opts.Style, so
+// a page that embeds the SVG inline can restyle it with CSS.
+func SVG(w io.Writer, b *board.Board, opts Options) error {
+	style := opts.Style.withDefaults()
+	cell := float64(opts.cellSize())
+	thin := math.Max(1, cell/48)
+	thick := math.Max(2, cell/16)
+	// The outer border is centred on the grid edge, so leave room for half
+	// of it all round.
+	pad := thick / 2
+	size := 9*cell + 2*pad
+
+	// The caption, if any, sits above the grid.
+	titleSize, subSize := cell*0.45, cell*0.3
+	header := 0.0
+	if opts.Title != "" || opts.Subtitle != "" {
+		header = cell / 4
+		if opts.Title != "" {
+			header += titleSize + cell/8
+		}
+		if opts.Subtitle != "" {
+			header += subSize + cell/8
+		}
+		header += cell / 8
+	}
+	height := size + header
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	fmt.Fprintf(bw, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%s\" height=\"%s\" viewBox=\"0 0 %s %s\">\n",
+		svgNum(size), svgNum(height), svgNum(size), svgNum(height))
+	if opts.Description != "" {
+		fmt.Fprintf(bw, "<desc>%s</desc>\n", html.EscapeString(opts.Description))
+	}
+	fmt.Fprintf(bw, "<style>\n")
+	fmt.Fprintf(bw, ".grid-line{stroke:%s;stroke-width:%s}\n", attr(style.Line), svgNum(thin))
+	fmt.Fprintf(bw, ".region-border{stroke:%s;stroke-width:%s;stroke-linecap:square;fill:none}\n", attr(style.Border), svgNum(thick))
+	fmt.Fprintf(bw, "text{font-family:%s;text-anchor:middle}\n", attr(style.Font))
+	fmt.Fprintf(bw, ".given{fill:%s;font-weight:%s}\n", attr(style.Given), attr(style.GivenWeight))
+	fmt.Fprintf(bw, ".filled{fill:%s;font-weight:%s}\n", attr(style.Filled), attr(style.FilledWeight))
+	fmt.Fprintf(bw, ".candidate,.subtitle{fill:%s}\n", attr(style.Candidate))
+	fmt.Fprintf(bw, ".title{fill:%s;font-weight:bold}\n", attr(style.Given))
+	fmt.Fprintf(bw, "</style>\n")
+	fmt.Fprintf(bw, "<rect width=\"100%%\" height=\"100%%\" fill=\"%s\"/>\n", attr(style.Background))
+
+	y := cell / 4
+	if opts.Title != "" {
+		y += titleSize
+		fmt.Fprintf(bw, "<text class=\"title\" x=\"%s\" y=\"%s\" font-size=\"%s\">%s</text>\n",
+			svgNum(size/2), svgNum(y), svgNum(titleSize), html.EscapeString(opts.Title))
+		y += cell / 8
+	}
+	if opts.Subtitle != "" {
+		y += subSize
+		fmt.Fprintf(bw, "<text class=\"subtitle\" x=\"%s\" y=\"%s\" font-size=\"%s\">%s</text>\n",
+			svgNum(size/2), svgNum(y), svgNum(subSize), html.EscapeString(opts.Subtitle))
+	}
+
+	// The grid goes below the caption.
+	fmt.Fprintf(bw, "<g transform=\"translate(0 %s)\">\n", svgNum(header))
+	if opts.Shade {
+		layout := b.Layout()
+		for pos := range board.CellCount {
+			fmt.Fprintf(bw, "<rect x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\" fill=\"%s\"/>\n",
+				svgNum(pad+float64(pos%9)*cell), svgNum(pad+float64(pos/9)*cell), svgNum(cell), svgNum(cell),
+				pastels[layout.PosToRegion[pos]%len(pastels)])
+		}
+	}
+
+	// Thin lines between all cells, then the region boundaries over them.
+	fmt.Fprintf(bw, "<g class=\"grid-line\">\n")
+	for i := 1; i < 9; i++ {
+		off := pad + float64(i)*cell
+		fmt.Fprintf(bw, "<line x1=\"%s\" y1=\"%s\" x2=\"%s\" y2=\"%s\"/>\n", svgNum(off), svgNum(pad), svgNum(off), svgNum(size-pad))
+		fmt.Fprintf(bw, "<line x1=\"%s\" y1=\"%s\" x2=\"%s\" y2=\"%s\"/>\n", svgNum(pad), svgNum(off), svgNum(size-pad), svgNum(off))
+	}
+	fmt.Fprintf(bw, "</g>\n")
+	fmt.Fprintf(bw, "<path class=\"region-border\" d=\"%s\"/>\n", borderPath(b.Layout(), pad, cell))
+
+	// Digits are about 0.7 em tall, so a baseline 0.35 em below the centre
+	// of the cell centres them.
+	fontSize := cell * 0.6
+	markSize := cell * 0.25
+	for pos := range board.CellCount {
+		cx := pad + float64(pos%9)*cell + cell/2
+		cy := pad + float64(pos/9)*cell + cell/2
+
+		class, val := "given", b.Get(pos)
+		if val == board.EmptyCell && opts.Solution != nil {
+			class, val = "filled", opts.Solution.Get(pos)
+		}
+		if val != board.EmptyCell {
+			fmt.Fprintf(bw, "<text class=\"%s\" x=\"%s\" y=\"%s\" font-size=\"%s\">%d</text>\n",
+				class, svgNum(cx), svgNum(cy+fontSize*0.35), svgNum(fontSize), val)
+			continue
+		}
+
+		// Candidates sit in a 3×3 grid within the cell, 1 at top left.
+		if opts.Candidates == nil {
+			continue
+		}
+		for digit := 1; digit <= 9; digit++ {
+			if opts.Candidates[pos]&(1<<(digit-1)) == 0 {
+				continue
+			}
+			mx := cx + float64((digit-1)%3-1)*cell*0.3
+			my := cy + float64((digit-1)/3-1)*cell*0.3
+			fmt.Fprintf(bw, "<text class=\"candidate\" x=\"%s\" y=\"%s\" font-size=\"%s\">%d</text>\n",
+				svgNum(mx), svgNum(my+markSize*0.35), svgNum(markSize), digit)
+		}
+	}
+
+	fmt.Fprintf(bw, "</g>\n</svg>\n")
+	return bw.Flush()
+}
+
+// borderPath returns SVG path data for the region boundaries of layout.
+// A boundary between two cells is marked on both, so the top and left
+// sides of every cell cover the inside and the last row and column add the
+// outer edge.
+func borderPath(layout *board.Layout, pad, cell float64) string {
+	var sb strings.Builder
+	for pos := range board.CellCount {
+		row, col := pos/9, pos%9
+		x, y := pad+float64(col)*cell, pad+float64(row)*cell
+		borders := layout.Borders(pos)
+		if borders&board.BorderTop != 0 {
+			fmt.Fprintf(&sb, "M%s %sh%s", svgNum(x), svgNum(y), svgNum(cell))
+		}
+		if borders&board.BorderLeft != 0 {
+			fmt.Fprintf(&sb, "M%s %sv%s", svgNum(x), svgNum(y), svgNum(cell))
+		}
+		if row == 8 {
+			fmt.Fprintf(&sb, "M%s %sh%s", svgNum(x), svgNum(y+cell), svgNum(cell))
+		}
+		if col == 8 {
+			fmt.Fprintf(&sb, "M%s %sv%s", svgNum(x+cell), svgNum(y), svgNum(cell))
+		}
+	}
+	return sb.String()
+}
+
+// svgNum formats a coordinate compactly with at most two decimals.
+func svgNum(f float64) string {
+	s := fmt.Sprintf("%.2f", f)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// attr escapes a style value for the document.
+func attr(s string) string {
+	return html.EscapeString(s)
+}
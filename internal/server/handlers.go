@@ -0,0 +1,385 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/rybkr/sudoku/internal/board"
+	"github.com/rybkr/sudoku/internal/generator"
+	"github.com/rybkr/sudoku/internal/solver"
+)
+
+// maxDifficultyRetries caps how many out-of-range puzzles /generate discards
+// before giving up; the request timeout usually ends the search first.
+const maxDifficultyRetries = 500
+
+// PuzzleRequest is the body of /solve, /validate, /rate and /hint.
+type PuzzleRequest struct {
+	Puzzle string `json:"puzzle"`
+	// Layout is the region map for jigsaw puzzles: 81 digits 0-8. Omit it
+	// for standard puzzles.
+	Layout string `json:"layout,omitempty"`
+}
+
+// GenerateRequest is the body of /generate. Every field is optional.
+type GenerateRequest struct {
+	// Clues is the number of givens. By default it is drawn from
+	// generator.RangeClues for the difficulty, or is
+	// generator.DefaultClueCount when no difficulty is given.
+	Clues int `json:"clues,omitempty"`
+	// Difficulty is a score, range or category as accepted by
+	// solver.ParseScoreRange, e.g. "hard" or "2.5:4.0".
+	Difficulty string `json:"difficulty,omitempty"`
+	// Type is "standard" (default) or "jigsaw".
+	Type string `json:"type,omitempty"`
+	// Seed makes the puzzle reproducible (0 = random).
+	Seed int64 `json:"seed,omitempty"`
+}
+
+// Board describes a board and its layout in responses.
+type Board struct {
+	Grid   string `json:"grid"`
+	Type   string `json:"type"`
+	Layout string `json:"layout"`
+	Clues  int    `json:"clues"`
+}
+
+// Difficulty is a solver.Rating in responses.
+type Difficulty struct {
+	Score    float64 `json:"score"`
+	Category string  `json:"category"`
+	// Hardest names the hardest technique used; it is empty when the
+	// logical solver could not finish the puzzle.
+	Hardest string `json:"hardest,omitempty"`
+	Steps   int    `json:"steps"`
+	Solved  bool   `json:"solved"`
+}
+
+// GenerateResponse is the reply to /generate.
+type GenerateResponse struct {
+	Puzzle     Board      `json:"puzzle"`
+	Solution   Board      `json:"solution"`
+	Difficulty Difficulty `json:"difficulty"`
+	Seed       int64      `json:"seed"`
+}
+
+// SolveResponse is the reply to /solve.
+type SolveResponse struct {
+	Solution Board `json:"solution"`
+}
+
+// ValidateResponse is the reply to /validate.
+type ValidateResponse struct {
+	// Valid reports whether the givens break no Sudoku rule.
+	Valid bool `json:"valid"`
+	// Solutions counts solutions up to 2; 1 means the puzzle is proper.
+	Solutions int  `json:"solutions"`
+	Unique    bool `json:"unique"`
+	// Conflicts lists the positions of givens that repeat a digit in a row,
+	// column or region.
+	Conflicts []int `json:"conflicts,omitempty"`
+}
+
+// RateResponse is the reply to /rate.
+type RateResponse struct {
+	Difficulty Difficulty `json:"difficulty"`
+	// Steps explains each deduction the logical solver made.
+	Steps []string `json:"steps"`
+}
+
+// Candidate is a digit at a position in responses.
+type Candidate struct {
+	Pos   int `json:"pos"`
+	Digit int `json:"digit"`
+}
+
+// Step is a solver.Step in responses.
+type Step struct {
+	Technique    string      `json:"technique"`
+	Description  string      `json:"description"`
+	Score        float64     `json:"score"`
+	Cells        []int       `json:"cells,omitempty"`
+	Placements   []Candidate `json:"placements,omitempty"`
+	Eliminations []Candidate `json:"eliminations,omitempty"`
+}
+
+// HintResponse is the reply to /hint. When no technique applies, Step is
+// omitted and Found is false.
+type HintResponse struct {
+	Found bool  `json:"found"`
+	Step  *Step `json:"step,omitempty"`
+}
+
+func (s *Server) handleGenerate(ctx context.Context, r *http.Request) (any, error) {
+	var req GenerateRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	if req.Clues != 0 && (req.Clues < generator.MinValidClueCount || req.Clues > generator.MaxValidClueCount) {
+		return nil, badRequest{generator.ErrInvalidClueCount}
+	}
+	diffRange, err := solver.ParseScoreRange(req.Difficulty)
+	if err != nil {
+		return nil, badRequest{err}
+	}
+	switch req.Type {
+	case "", "standard", "jigsaw":
+	default:
+		return nil, badRequestf("unknown board type %q: must be standard or jigsaw", req.Type)
+	}
+
+	// Without a clue count, draw one from where the difficulty is common.
+	minClues, maxClues := generator.DefaultClueCount, generator.DefaultClueCount
+	switch {
+	case req.Clues != 0:
+		minClues, maxClues = req.Clues, req.Clues
+	case req.Difficulty != "":
+		minClues, maxClues = generator.RangeClues(diffRange, req.Type)
+	}
+	if req.Seed == 0 {
+		req.Seed = time.Now().UnixNano()
+	}
+
+	// A one-puzzle pool gives the same seeded stream as 'sudoku gen'.
+	pool := &generator.Pool{
+		Workers:    1,
+		Seed:       req.Seed,
+		MaxRetries: maxDifficultyRetries,
+		Accept: func(r solver.Rating) bool {
+			return diffRange.Contains(r.Score)
+		},
+		Options: func(_ int, rng *rand.Rand) *generator.Options {
+			opts := generator.DefaultOptions(minClues + rng.Intn(maxClues-minClues+1))
+			opts.Timeout = 0
+			if req.Type == "jigsaw" {
+				opts.Layout = board.RandomJigsawLayout(rng)
+			}
+			return opts
+		},
+	}
+	res, ok := <-pool.Run(ctx, 1)
+	if !ok {
+		return nil, ctx.Err()
+	}
+	if res.Err != nil {
+		return nil, res.Err
+	}
+
+	return GenerateResponse{
+		Puzzle:     boardOf(res.Puzzle),
+		Solution:   boardOf(res.Solution),
+		Difficulty: difficultyOf(res.Rating),
+		Seed:       req.Seed,
+	}, nil
+}
+
+func (s *Server) handleSolve(ctx context.Context, r *http.Request) (any, error) {
+	b, err := decodePuzzle(r)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := solver.DefaultOptions()
+	opts.MaxSolutions = 2
+	opts.Timeout = 0
+	opts.Engine = solver.DancingLinks
+	solution, err := solver.New(b, opts).SolveContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return SolveResponse{Solution: boardOf(solution)}, nil
+}
+
+func (s *Server) handleValidate(ctx context.Context, r *http.Request) (any, error) {
+	var req PuzzleRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	layout, err := parseLayout(req.Layout)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the givens without the board's rule checks so that every
+	// conflicting cell can be reported, not just the first.
+	if len(req.Puzzle) != board.CellCount {
+		return nil, badRequestf("puzzle must be exactly %d characters, got %d", board.CellCount, len(req.Puzzle))
+	}
+	b := board.New(layout)
+	for pos := range board.CellCount {
+		switch ch := req.Puzzle[pos]; {
+		case ch >= '1' && ch <= '9':
+			b.SetForce(pos, int(ch-'0'))
+		case ch == '.' || ch == '0':
+		default:
+			return nil, badRequestf("invalid character %q at position %d", ch, pos)
+		}
+	}
+
+	resp := ValidateResponse{Conflicts: conflicts(req.Puzzle, layout)}
+	resp.Valid = len(resp.Conflicts) == 0
+	if !resp.Valid {
+		return resp, nil
+	}
+
+	resp.Solutions, err = solver.CountSolutionsContext(ctx, b, 2)
+	if err != nil {
+		return nil, err
+	}
+	resp.Unique = resp.Solutions == 1
+	return resp, nil
+}
+
+func (s *Server) handleRate(ctx context.Context, r *http.Request) (any, error) {
+	b, err := decodePuzzle(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkUnique(ctx, b); err != nil {
+		return nil, err
+	}
+
+	result, err := solver.SolveLogicalContext(ctx, b)
+	if err != nil {
+		return nil, err
+	}
+	rating := solver.RateResult(result)
+
+	steps := make([]string, len(result.Steps))
+	for i, step := range result.Steps {
+		steps[i] = step.String()
+	}
+	return RateResponse{Difficulty: difficultyOf(rating), Steps: steps}, nil
+}
+
+func (s *Server) handleHint(ctx context.Context, r *http.Request) (any, error) {
+	b, err := decodePuzzle(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkUnique(ctx, b); err != nil {
+		return nil, err
+	}
+
+	step, ok, err := solver.NewLogical(b).NextContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return HintResponse{}, nil
+	}
+	return HintResponse{Found: true, Step: stepOf(step)}, nil
+}
+
+// decodePuzzle decodes a PuzzleRequest and parses its board.
+func decodePuzzle(r *http.Request) (*board.Board, error) {
+	var req PuzzleRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	layout, err := parseLayout(req.Layout)
+	if err != nil {
+		return nil, err
+	}
+	b, err := board.NewFromString(req.Puzzle, layout)
+	if err != nil {
+		if errors.Is(err, board.ErrIllegalMove) {
+			return nil, fmt.Errorf("%w: %v", solver.ErrInvalidPuzzle, err)
+		}
+		return nil, badRequest{err}
+	}
+	return b, nil
+}
+
+// parseLayout parses an optional region map; "" means the standard layout.
+func parseLayout(s string) (*board.Layout, error) {
+	if s == "" {
+		return board.StandardLayout(), nil
+	}
+	layout, err := board.ParseLayout(s)
+	if err != nil {
+		return nil, badRequest{err}
+	}
+	return layout, nil
+}
+
+// checkUnique returns an error unless the puzzle has exactly one solution,
+// since ratings and hints are meaningless otherwise.
+func checkUnique(ctx context.Context, b *board.Board) error {
+	n, err := solver.CountSolutionsContext(ctx, b, 2)
+	switch {
+	case err != nil:
+		return err
+	case n == 0:
+		return solver.ErrNoSolution
+	case n > 1:
+		return solver.ErrMultipleSolutions
+	}
+	return nil
+}
+
+// conflicts returns the positions of givens that share a digit with another
+// given in a row, column or region.
+func conflicts(puzzle string, layout *board.Layout) []int {
+	var found []int
+	for a := range board.CellCount {
+		if puzzle[a] < '1' || puzzle[a] > '9' {
+			continue
+		}
+		for b := range board.CellCount {
+			if a == b || puzzle[a] != puzzle[b] {
+				continue
+			}
+			if a/9 == b/9 || a%9 == b%9 || layout.PosToRegion[a] == layout.PosToRegion[b] {
+				found = append(found, a)
+				break
+			}
+		}
+	}
+	return found
+}
+
+// boardOf describes a board for a response.
+func boardOf(b *board.Board) Board {
+	return Board{
+		Grid:   b.String(),
+		Type:   b.Layout().Type,
+		Layout: b.Layout().String(),
+		Clues:  b.ClueCount(),
+	}
+}
+
+// difficultyOf describes a rating for a response.
+func difficultyOf(r solver.Rating) Difficulty {
+	d := Difficulty{
+		Score:    r.Score,
+		Category: r.Category.String(),
+		Steps:    r.Steps,
+		Solved:   r.Solved,
+	}
+	if r.Solved {
+		d.Hardest = r.Hardest.String()
+	}
+	return d
+}
+
+// stepOf describes a logical step for a response.
+func stepOf(step solver.Step) *Step {
+	s := &Step{
+		Technique:   step.Technique.String(),
+		Description: step.String(),
+		Score:       step.Score(),
+		Cells:       step.Cells,
+	}
+	for _, p := range step.Placements {
+		s.Placements = append(s.Placements, Candidate{Pos: p.Pos, Digit: p.Digit})
+	}
+	for _, e := range step.Eliminations {
+		s.Eliminations = append(s.Eliminations, Candidate{Pos: e.Pos, Digit: e.Digit})
+	}
+	return s
+}
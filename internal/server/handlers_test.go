@@ -0,0 +1,176 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// easyPuzzle is a proper puzzle the logical solver rates Easy (1.2).
+const easyPuzzle = "..4.9.......7..6..1.....5.2.96.5..2432.84..6...7..635.63.1..4..7....2..3.5...8..6"
+
+// conflictPuzzle repeats a 5 in its first row.
+var conflictPuzzle = "55" + strings.Repeat(".", 79)
+
+// emptyPuzzle has every grid as a solution.
+var emptyPuzzle = strings.Repeat(".", 81)
+
+// checkSolution checks that solution is complete and keeps the givens of
+// puzzle.
+func checkSolution(t *testing.T, puzzle, solution string) {
+	t.Helper()
+	if len(solution) != len(puzzle) || strings.Contains(solution, ".") {
+		t.Fatalf("solution %q is not a complete grid", solution)
+	}
+	for i := range puzzle {
+		if puzzle[i] != '.' && puzzle[i] != solution[i] {
+			t.Fatalf("solution %q changes the given at %d of %q", solution, i, puzzle)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	s := New(Config{})
+	tests := []struct {
+		name, body, typ string
+		clues           int
+	}{
+		{"clues", `{"clues":30,"seed":7}`, "standard", 30},
+		{"jigsaw", `{"clues":30,"type":"jigsaw","seed":7}`, "jigsaw", 30},
+		{"difficulty", `{"difficulty":"easy","seed":7}`, "standard", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reply GenerateResponse
+			decodeReply(t, post(s, "/generate", tt.body), http.StatusOK, &reply)
+			checkSolution(t, reply.Puzzle.Grid, reply.Solution.Grid)
+			if reply.Puzzle.Type != tt.typ || len(reply.Puzzle.Layout) != 81 {
+				t.Errorf("board type %q, layout %q; want %s", reply.Puzzle.Type, reply.Puzzle.Layout, tt.typ)
+			}
+			if tt.clues != 0 && reply.Puzzle.Clues != tt.clues {
+				t.Errorf("%d clues, want %d", reply.Puzzle.Clues, tt.clues)
+			}
+			if reply.Seed != 7 {
+				t.Errorf("seed %d, want 7", reply.Seed)
+			}
+
+			// The seed makes the reply reproducible.
+			var again GenerateResponse
+			decodeReply(t, post(s, "/generate", tt.body), http.StatusOK, &again)
+			if again.Puzzle.Grid != reply.Puzzle.Grid {
+				t.Errorf("seed 7 gave %s, then %s", reply.Puzzle.Grid, again.Puzzle.Grid)
+			}
+		})
+	}
+
+	t.Run("difficulty is honoured", func(t *testing.T) {
+		var reply GenerateResponse
+		decodeReply(t, post(s, "/generate", `{"difficulty":"easy","seed":7}`), http.StatusOK, &reply)
+		if reply.Difficulty.Category != "Easy" {
+			t.Errorf("category %q, want Easy", reply.Difficulty.Category)
+		}
+	})
+}
+
+func TestSolve(t *testing.T) {
+	var reply SolveResponse
+	decodeReply(t, post(New(Config{}), "/solve", `{"puzzle":"`+easyPuzzle+`"}`), http.StatusOK, &reply)
+	checkSolution(t, easyPuzzle, reply.Solution.Grid)
+}
+
+func TestValidate(t *testing.T) {
+	s := New(Config{})
+	tests := []struct {
+		name   string
+		puzzle string
+		want   ValidateResponse
+	}{
+		{"proper", easyPuzzle, ValidateResponse{Valid: true, Solutions: 1, Unique: true}},
+		{"several solutions", emptyPuzzle, ValidateResponse{Valid: true, Solutions: 2}},
+		{"conflict", conflictPuzzle, ValidateResponse{Conflicts: []int{0, 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reply ValidateResponse
+			decodeReply(t, post(s, "/validate", `{"puzzle":"`+tt.puzzle+`"}`), http.StatusOK, &reply)
+			if reply.Valid != tt.want.Valid || reply.Solutions != tt.want.Solutions ||
+				reply.Unique != tt.want.Unique || len(reply.Conflicts) != len(tt.want.Conflicts) {
+				t.Fatalf("got %+v, want %+v", reply, tt.want)
+			}
+			for i, pos := range tt.want.Conflicts {
+				if reply.Conflicts[i] != pos {
+					t.Errorf("conflicts %v, want %v", reply.Conflicts, tt.want.Conflicts)
+				}
+			}
+		})
+	}
+}
+
+func TestRate(t *testing.T) {
+	var reply RateResponse
+	decodeReply(t, post(New(Config{}), "/rate", `{"puzzle":"`+easyPuzzle+`"}`), http.StatusOK, &reply)
+	want := Difficulty{Score: 1.2, Category: "Easy", Hardest: "Hidden Single", Solved: true}
+	got := reply.Difficulty
+	if got.Score != want.Score || got.Category != want.Category || got.Hardest != want.Hardest || !got.Solved {
+		t.Errorf("rated %+v, want %+v", got, want)
+	}
+	if got.Steps != len(reply.Steps) || len(reply.Steps) == 0 {
+		t.Errorf("%d steps described, rating counts %d", len(reply.Steps), got.Steps)
+	}
+}
+
+func TestHint(t *testing.T) {
+	s := New(Config{})
+	var solved SolveResponse
+	decodeReply(t, post(s, "/solve", `{"puzzle":"`+easyPuzzle+`"}`), http.StatusOK, &solved)
+
+	var reply HintResponse
+	decodeReply(t, post(s, "/hint", `{"puzzle":"`+easyPuzzle+`"}`), http.StatusOK, &reply)
+	if !reply.Found || reply.Step == nil {
+		t.Fatalf("no hint for %s", easyPuzzle)
+	}
+	if len(reply.Step.Placements) == 0 && len(reply.Step.Eliminations) == 0 {
+		t.Fatalf("hint %+v changes nothing", reply.Step)
+	}
+	// A hint never contradicts the solution.
+	for _, p := range reply.Step.Placements {
+		if want := solved.Solution.Grid[p.Pos]; byte('0'+p.Digit) != want {
+			t.Errorf("hint places %d at %d, the solution has %c", p.Digit, p.Pos, want)
+		}
+	}
+	for _, e := range reply.Step.Eliminations {
+		if byte('0'+e.Digit) == solved.Solution.Grid[e.Pos] {
+			t.Errorf("hint eliminates the solution's %d at %d", e.Digit, e.Pos)
+		}
+	}
+}
+
+func TestBadRequests(t *testing.T) {
+	s := New(Config{})
+	tests := []struct {
+		name, path, body string
+		status           int
+	}{
+		{"malformed JSON", "/solve", `{"puzzle":`, http.StatusBadRequest},
+		{"unknown field", "/solve", `{"grid":"` + easyPuzzle + `"}`, http.StatusBadRequest},
+		{"short puzzle", "/rate", `{"puzzle":"..4.9"}`, http.StatusBadRequest},
+		{"bad layout", "/hint", `{"puzzle":"` + easyPuzzle + `","layout":"012"}`, http.StatusBadRequest},
+		{"clue count", "/generate", `{"clues":5}`, http.StatusBadRequest},
+		{"board type", "/generate", `{"type":"hex"}`, http.StatusBadRequest},
+		{"difficulty", "/generate", `{"difficulty":"impossible"}`, http.StatusBadRequest},
+		{"validate length", "/validate", `{"puzzle":"123"}`, http.StatusBadRequest},
+
+		{"solve conflict", "/solve", `{"puzzle":"` + conflictPuzzle + `"}`, http.StatusUnprocessableEntity},
+		{"rate several solutions", "/rate", `{"puzzle":"` + emptyPuzzle + `"}`, http.StatusUnprocessableEntity},
+		{"hint several solutions", "/hint", `{"puzzle":"` + emptyPuzzle + `"}`, http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reply errorResponse
+			decodeReply(t, post(s, tt.path, tt.body), tt.status, &reply)
+			if reply.Error == "" {
+				t.Error("reply has no error message")
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+// Package server exposes the generator, solver and rater as a JSON HTTP API.
+//
+// Every endpoint takes a POST with a JSON body and answers with JSON. Boards
+// are 81-character strings in row-major order with '.' for empty cells, and
+// cell positions are indices 0-80 into that string. Errors are reported as
+// {"error": "..."} with a matching status code.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/rybkr/sudoku/internal/generator"
+	"github.com/rybkr/sudoku/internal/solver"
+)
+
+// Defaults applied by New to zero Config fields.
+const (
+	DefaultRequestTimeout = 10 * time.Second
+	DefaultMaxBodyBytes   = 64 << 10
+)
+
+var (
+	// errRequestTimeout is the cause recorded when a request's own timeout
+	// expires, to tell it apart from the client going away.
+	errRequestTimeout = errors.New("request timeout exceeded")
+	errBusy           = errors.New("server busy, try again later")
+)
+
+// Config configures a Server.
+type Config struct {
+	// RequestTimeout bounds the work done for each request, including time
+	// spent waiting for a slot (0 = DefaultRequestTimeout).
+	RequestTimeout time.Duration
+	// MaxConcurrent is how many requests may run at once (<= 0 means
+	// GOMAXPROCS). Further requests wait for a slot until their timeout.
+	MaxConcurrent int
+	// MaxBodyBytes caps request bodies (0 = DefaultMaxBodyBytes).
+	MaxBodyBytes int64
+}
+
+// Server is an http.Handler serving the Sudoku API. The only state shared
+// between requests is the set of slots that limits how many run at once, so
+// it can be used directly with httptest.
+type Server struct {
+	config Config
+	mux    *http.ServeMux
+	slots  chan struct{}
+}
+
+// New creates a Server with the given configuration.
+func New(config Config) *Server {
+	if config.RequestTimeout <= 0 {
+		config.RequestTimeout = DefaultRequestTimeout
+	}
+	if config.MaxConcurrent <= 0 {
+		config.MaxConcurrent = runtime.GOMAXPROCS(0)
+	}
+	if config.MaxBodyBytes <= 0 {
+		config.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+
+	s := &Server{
+		config: config,
+		mux:    http.NewServeMux(),
+		slots:  make(chan struct{}, config.MaxConcurrent),
+	}
+	s.mux.Handle("POST /generate", s.limit(s.handleGenerate))
+	s.mux.Handle("POST /solve", s.limit(s.handleSolve))
+	s.mux.Handle("POST /validate", s.limit(s.handleValidate))
+	s.mux.Handle("POST /rate", s.limit(s.handleRate))
+	s.mux.Handle("POST /hint", s.limit(s.handleHint))
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// handlerFunc is an endpoint: it decodes r, does the work under ctx and
+// returns the response body or an error.
+type handlerFunc func(ctx context.Context, r *http.Request) (any, error)
+
+// limit wraps an endpoint with the request timeout, the concurrency limit
+// and JSON encoding of its result.
+func (s *Server) limit(h handlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeoutCause(r.Context(), s.config.RequestTimeout, errRequestTimeout)
+		defer cancel()
+
+		select {
+		case s.slots <- struct{}{}:
+			defer func() { <-s.slots }()
+		case <-ctx.Done():
+			writeError(w, errBusy)
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, s.config.MaxBodyBytes)
+		resp, err := h(ctx, r)
+		if err != nil {
+			// Library code reports its own timeout sentinel or ctx.Err();
+			// either way a done context means the request ran out of time.
+			if ctx.Err() != nil && errors.Is(context.Cause(ctx), errRequestTimeout) {
+				err = errRequestTimeout
+			}
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, resp)
+	})
+}
+
+// badRequest marks an error caused by a malformed request.
+type badRequest struct{ err error }
+
+func (e badRequest) Error() string { return e.err.Error() }
+func (e badRequest) Unwrap() error { return e.err }
+
+// badRequestf returns a badRequest error with a formatted message.
+func badRequestf(format string, args ...any) error {
+	return badRequest{fmt.Errorf(format, args...)}
+}
+
+// decode reads the JSON request body into v, rejecting unknown fields.
+func decode(r *http.Request, v any) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return badRequestf("invalid JSON body: %w", err)
+	}
+	return nil
+}
+
+// errorResponse is the body of every error reply.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// statusOf maps an error to its HTTP status code.
+func statusOf(err error) int {
+	var br badRequest
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.As(err, &br):
+		return http.StatusBadRequest
+	case errors.Is(err, errBusy):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, errRequestTimeout),
+		errors.Is(err, solver.ErrTimeout),
+		errors.Is(err, generator.ErrTimeout):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, solver.ErrInvalidPuzzle),
+		errors.Is(err, solver.ErrNoSolution),
+		errors.Is(err, solver.ErrMultipleSolutions),
+		errors.Is(err, generator.ErrDifficultyOutOfRange),
+		errors.Is(err, generator.ErrInvalidClueCount):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, context.Canceled):
+		// The client went away, so nobody sees the status; log it the way
+		// nginx does.
+		return 499
+	}
+	return http.StatusInternalServerError
+}
+
+// writeError replies with err and its status code.
+func writeError(w http.ResponseWriter, err error) {
+	writeJSON(w, statusOf(err), errorResponse{Error: err.Error()})
+}
+
+// writeJSON replies with v encoded as JSON.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,136 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rybkr/sudoku/internal/generator"
+	"github.com/rybkr/sudoku/internal/solver"
+)
+
+// post sends body to path and returns the recorded reply.
+func post(s http.Handler, path, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+	return rec
+}
+
+// decodeReply checks the status of rec and decodes its body into v.
+func decodeReply(t *testing.T, rec *httptest.ResponseRecorder, status int, v any) {
+	t.Helper()
+	if rec.Code != status {
+		t.Fatalf("status %d, want %d: %s", rec.Code, status, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type %q, want application/json", ct)
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("decoding %s: %v", rec.Body, err)
+	}
+}
+
+func TestStatusOf(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{badRequestf("bad"), http.StatusBadRequest},
+		{badRequest{generator.ErrInvalidClueCount}, http.StatusBadRequest},
+		{badRequestf("invalid JSON body: %w", &http.MaxBytesError{Limit: 1}), http.StatusRequestEntityTooLarge},
+		{errBusy, http.StatusServiceUnavailable},
+		{errRequestTimeout, http.StatusGatewayTimeout},
+		{fmt.Errorf("solving: %w", solver.ErrTimeout), http.StatusGatewayTimeout},
+		{generator.ErrTimeout, http.StatusGatewayTimeout},
+		{solver.ErrInvalidPuzzle, http.StatusUnprocessableEntity},
+		{solver.ErrNoSolution, http.StatusUnprocessableEntity},
+		{solver.ErrMultipleSolutions, http.StatusUnprocessableEntity},
+		{generator.ErrDifficultyOutOfRange, http.StatusUnprocessableEntity},
+		{generator.ErrInvalidClueCount, http.StatusUnprocessableEntity},
+		{context.Canceled, 499},
+		{errors.New("boom"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		if got := statusOf(tt.err); got != tt.want {
+			t.Errorf("statusOf(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	rec := httptest.NewRecorder()
+	New(Config{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/solve", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /solve: status %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestBodyTooLarge(t *testing.T) {
+	s := New(Config{MaxBodyBytes: 16})
+	var reply errorResponse
+	decodeReply(t, post(s, "/solve", `{"puzzle":"`+strings.Repeat(".", 81)+`"}`), http.StatusRequestEntityTooLarge, &reply)
+}
+
+// TestBusy fills every slot, so a request waits out its timeout for one.
+func TestBusy(t *testing.T) {
+	s := New(Config{MaxConcurrent: 1, RequestTimeout: 20 * time.Millisecond})
+	s.slots <- struct{}{}
+	defer func() { <-s.slots }()
+
+	var reply errorResponse
+	decodeReply(t, post(s, "/solve", `{"puzzle":"`+strings.Repeat(".", 81)+`"}`), http.StatusServiceUnavailable, &reply)
+	if reply.Error != errBusy.Error() {
+		t.Errorf("error %q, want %q", reply.Error, errBusy)
+	}
+}
+
+// TestTimeout runs an endpoint that works until its context is done, which
+// happens when the request timeout expires.
+func TestTimeout(t *testing.T) {
+	s := New(Config{RequestTimeout: 20 * time.Millisecond})
+	h := s.limit(func(ctx context.Context, r *http.Request) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	var reply errorResponse
+	decodeReply(t, post(h, "/", "{}"), http.StatusGatewayTimeout, &reply)
+	if reply.Error != errRequestTimeout.Error() {
+		t.Errorf("error %q, want %q", reply.Error, errRequestTimeout)
+	}
+}
+
+// TestClientGone checks that a client hanging up is not reported as a
+// timeout.
+func TestClientGone(t *testing.T) {
+	s := New(Config{})
+	ctx, hangUp := context.WithCancel(context.Background())
+	h := s.limit(func(ctx context.Context, r *http.Request) (any, error) {
+		hangUp()
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequestWithContext(ctx, http.MethodPost, "/", strings.NewReader("{}")))
+	if rec.Code != 499 {
+		t.Errorf("status %d, want 499", rec.Code)
+	}
+}
+
+// TestSlotsReleased checks that each request gives its slot back, so that
+// a server with one slot serves requests one after another.
+func TestSlotsReleased(t *testing.T) {
+	s := New(Config{MaxConcurrent: 1, RequestTimeout: time.Second})
+	for i := range 3 {
+		if rec := post(s, "/validate", `{"puzzle":"`+easyPuzzle+`"}`); rec.Code != http.StatusOK {
+			t.Fatalf("request %d: status %d: %s", i+1, rec.Code, rec.Body)
+		}
+	}
+}
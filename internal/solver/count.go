@@ -0,0 +1,121 @@
+package solver
+
+import (
+	"context"
+	"iter"
+
+	"github.com/rybkr/sudoku/internal/board"
+)
+
+// CountSolutions counts the solutions of a puzzle, stopping as soon as limit
+// solutions have been found (limit <= 0 counts every solution).
+// A count of 0 means the puzzle has no solution and 1 that it is unique.
+// Returns ErrInvalidPuzzle for boards that break Sudoku rules.
+func CountSolutions(b *board.Board, limit int) (int, error) {
+	return CountSolutionsContext(context.Background(), b, limit)
+}
+
+// CountSolutionsContext is like CountSolutions but stops when ctx is done,
+// returning the solutions counted so far and ctx.Err().
+func CountSolutionsContext(ctx context.Context, b *board.Board, limit int) (int, error) {
+	opts := DefaultOptions()
+	opts.MaxSolutions = limit
+	opts.Timeout = 0
+	return New(b, opts).CountContext(ctx)
+}
+
+// Solutions returns an iterator over every solution of a puzzle.
+// Each solution is yielded with a nil error. If the puzzle is invalid or ctx
+// is done before the search finishes, a final nil board is yielded with the
+// error. Stopping the iteration early is always safe.
+func Solutions(ctx context.Context, b *board.Board) iter.Seq2[*board.Board, error] {
+	opts := DefaultOptions()
+	opts.MaxSolutions = 0
+	opts.Timeout = 0
+	opts.Context = ctx
+	return New(b, opts).Solutions()
+}
+
+// Count counts the puzzle's solutions, up to MaxSolutions (0 = unlimited).
+func (s *Solver) Count() (int, error) {
+	return s.CountContext(s.options.Context)
+}
+
+// CountContext is like Count but stops when ctx is done. Errors are reported
+// as in SolveContext.
+func (s *Solver) CountContext(ctx context.Context) (int, error) {
+	if !s.Board.IsValid() {
+		return 0, ErrInvalidPuzzle
+	}
+
+	ctx, cancel := s.makeContext(ctx)
+	defer cancel()
+
+	n, err := newDLX(s.Board, nil).count(ctx, s.options.MaxSolutions)
+	if err != nil {
+		return n, contextErr(ctx)
+	}
+	return n, nil
+}
+
+// Solutions returns an iterator over the puzzle's solutions, yielding at most
+// MaxSolutions of them (0 = unlimited). Errors are reported as in Solutions.
+func (s *Solver) Solutions() iter.Seq2[*board.Board, error] {
+	return func(yield func(*board.Board, error) bool) {
+		if !s.Board.IsValid() {
+			yield(nil, ErrInvalidPuzzle)
+			return
+		}
+
+		ctx, cancel := s.makeContext(s.options.Context)
+		defer cancel()
+
+		rng := s.rng
+		if !s.options.Randomize {
+			rng = nil
+		}
+
+		n, stopped := 0, false
+		err := newDLX(s.Board, rng).each(ctx, func(b *board.Board) bool {
+			n++
+			if !yield(b, nil) {
+				stopped = true
+				return false
+			}
+			return s.options.MaxSolutions <= 0 || n < s.options.MaxSolutions
+		})
+		if err != nil && !stopped {
+			yield(nil, contextErr(ctx))
+		}
+	}
+}
+
+// solveUnique finds a solution and verifies that it is the only one.
+func (s *Solver) solveUnique(ctx context.Context) (*board.Board, error) {
+	var solution *board.Board
+	count := 0
+	opts := *s.options
+	opts.MaxSolutions = 2
+	opts.Timeout = 0
+	opts.Context = ctx
+	unique := &Solver{Board: s.Board, options: &opts, rng: s.rng}
+	for b, err := range unique.Solutions() {
+		if err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			solution = b
+		}
+		count++
+	}
+
+	switch count {
+	case 0:
+		return nil, ErrNoSolution
+	case 1:
+		s.Board = solution
+		return solution, nil
+	default:
+		return nil, ErrMultipleSolutions
+	}
+}
@@ -1,32 +1,295 @@
 package solver
 
 import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
 	"github.com/rybkr/sudoku/internal/board"
 )
 
-// Difficulty returns an integer measure of a board's difficulty.
-func Difficulty(b *board.Board) int {
-	s := New(b, nil)
-	return s.traceDifficulty()
+// Difficulty scores follow the spirit of Sudoku Explainer's (SE) rating: a
+// puzzle is as hard as the hardest single step needed to solve it, and every
+// technique has a fixed score. Techniques SE rates keep SE's score; the
+// single-digit patterns (Skyscraper, 2-String Kite), W-Wing and Simple
+// Coloring, which SE lacks, sit between the wings and fish they compete with.
+//
+//	1.2  Hidden Single (region)      3.8  Swordfish
+//	1.5  Hidden Single (row/column)  4.0  Hidden Triple, Skyscraper
+//	2.3  Naked Single                4.1  2-String Kite
+//	2.6  Pointing Candidates         4.2  XY-Wing
+//	2.8  Claiming Candidates         4.4  XYZ-Wing, W-Wing
+//	3.0  Naked Pair                  4.5  Simple Coloring
+//	3.2  X-Wing                      5.0  Naked Quad
+//	3.4  Hidden Pair                 5.2  Jellyfish
+//	3.6  Naked Triple                5.4  Hidden Quad
+//
+// Puzzles the LogicalSolver cannot finish need chains or trial and error and
+// are rated TrialScore.
+//
+// These reference puzzles show the scale, one or two per category:
+//
+//	1.2  Easy        ..4.9.......7..6..1.....5.2.96.5..2432.84..6...7..635.63.1..4..7....2..3.5...8..6
+//	1.5  Easy        837.5..9...5..43.7...............2.......85..15.2...8....8..9.1.7.6.2...4.2......
+//	2.3  Medium      ......1.93.5...7...9...6.3..39.82..7.5..7..9.8..5.......6..587.54..2..6..1.4.....
+//	2.8  Medium      54....12....29...8........348.3...6......84.9.7..1.....97..2........4...1.....37.
+//	3.2  Hard        ..5.........6...91.9.23..86.8.3...5..1...6...4........9..............3.42.3..471.
+//	4.0  Hard        .7....9..3..5..7...86.3.....9........476..8.26......1.8..1.5...........9..9.7..34
+//	4.2  Expert      .73...5.....3..16....94...79..15.4..7.5............7.......8.3.....2.97.698.3....
+//	5.0  Expert      ..43.9.7......6.197.......5.38.9.......1...6...2.......4..5...3.....1.57..7...2..
+//	7.0  Diabolical  .63.....15.....9..978.........43....13..5274...57..6..2...43...7.4.8...5.....5.6.
+const TrialScore = 7.0
+
+var techniqueScores = [techniqueCount]float64{
+	HiddenSingle:       1.5,
+	NakedSingle:        2.3,
+	PointingCandidates: 2.6,
+	ClaimingCandidates: 2.8,
+	NakedPair:          3.0,
+	XWing:              3.2,
+	HiddenPair:         3.4,
+	NakedTriple:        3.6,
+	Swordfish:          3.8,
+	HiddenTriple:       4.0,
+	Skyscraper:         4.0,
+	TwoStringKite:      4.1,
+	XYWing:             4.2,
+	XYZWing:            4.4,
+	WWing:              4.4,
+	SimpleColoring:     4.5,
+	NakedQuad:          5.0,
+	Jellyfish:          5.2,
+	HiddenQuad:         5.4,
+}
+
+// Score returns the technique's rating on the SE-like scale.
+func (t Technique) Score() float64 {
+	if t < 0 || t >= techniqueCount {
+		return TrialScore
+	}
+	return techniqueScores[t]
+}
+
+// Score returns the rating of a single step. It matches the technique score
+// except that hidden singles found in a region are rated as easier.
+func (s Step) Score() float64 {
+	if s.Technique == HiddenSingle && len(s.Units) > 0 && s.Units[0].Kind == Region {
+		return 1.2
+	}
+	return s.Technique.Score()
+}
+
+// Category is a coarse difficulty label derived from a score.
+type Category int
+
+const (
+	Easy       Category = iota // hidden singles (score < 2.0)
+	Medium                     // naked singles, locked candidates (score < 3.0)
+	Hard                       // subsets, fish, single-digit patterns (score < 4.2)
+	Expert                     // wings, coloring, quads, Jellyfish (score < 6.0)
+	Diabolical                 // beyond the logical solver (score ≥ 6.0)
+)
+
+// categoryBounds holds the lowest score of each category; the upper bound of
+// a category is the lower bound of the next. They are set where the
+// generator's output divides: random digging mostly yields puzzles that need
+// nothing past locked candidates, so the harder categories each start at the
+// technique group that first appears in a useful share of puzzles.
+// generator.CategoryClues gives clue counts that produce each category.
+var categoryBounds = [...]float64{
+	Easy:       0,
+	Medium:     2.0,
+	Hard:       3.0,
+	Expert:     4.2,
+	Diabolical: 6.0,
+}
+
+var categoryNames = [...]string{
+	Easy:       "Easy",
+	Medium:     "Medium",
+	Hard:       "Hard",
+	Expert:     "Expert",
+	Diabolical: "Diabolical",
 }
 
-// traceDifficulty implements the measure of a board's difficulty.
-func (s *Solver) traceDifficulty() int {
-	if s.Board.EmptyCount() == 0 {
-		return 0
+// String returns the category label.
+func (c Category) String() string {
+	if c < Easy || c > Diabolical {
+		return fmt.Sprintf("Category(%d)", int(c))
 	}
+	return categoryNames[c]
+}
+
+// Range returns the half-open score interval [min, max) covered by the
+// category. The max of Diabolical is TrialScore, inclusive.
+func (c Category) Range() (min, max float64) {
+	if c == Diabolical {
+		return categoryBounds[c], TrialScore
+	}
+	return categoryBounds[c], categoryBounds[c+1]
+}
 
-	cell, candidates := s.FindMRVCell()
-	if len(candidates) == 0 {
-		// If no tiles can be placed, nothing more can be done.
-		return 0
+// CategoryOf returns the category a score falls into.
+func CategoryOf(score float64) Category {
+	c := Easy
+	for next := Medium; next <= Diabolical; next++ {
+		if score >= categoryBounds[next] {
+			c = next
+		}
 	}
+	return c
+}
+
+// ParseCategory parses a case-insensitive category label such as "hard".
+func ParseCategory(s string) (Category, error) {
+	for c, name := range categoryNames {
+		if strings.EqualFold(strings.TrimSpace(s), name) {
+			return Category(c), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown difficulty %q: must be one of %s", s, strings.ToLower(strings.Join(categoryNames[:], ", ")))
+}
+
+// ScoreRange is a range of accepted difficulty scores.
+// Bounds given as numbers are inclusive; an upper bound given as a category
+// name excludes the first score of the next category.
+type ScoreRange struct {
+	Min, Max     float64
+	MaxExclusive bool
+}
+
+// AnyScore is the range that accepts every rating.
+var AnyScore = ScoreRange{Min: 0, Max: TrialScore}
+
+// Contains reports whether a score lies within the range.
+func (r ScoreRange) Contains(score float64) bool {
+	if score < r.Min {
+		return false
+	}
+	if r.MaxExclusive {
+		return score < r.Max
+	}
+	return score <= r.Max
+}
 
-	score := 0
-	for _, candidate := range candidates {
-		s.Board.SetForce(cell, candidate)
-		score += 1 + s.traceDifficulty()
-		s.Board.Clear(cell)
+// String returns the range in interval notation, e.g. "[2.5, 4.5)".
+func (r ScoreRange) String() string {
+	if r.Min == r.Max {
+		return fmt.Sprintf("%.1f", r.Min)
+	}
+	if r.MaxExclusive {
+		return fmt.Sprintf("[%.1f, %.1f)", r.Min, r.Max)
+	}
+	return fmt.Sprintf("[%.1f, %.1f]", r.Min, r.Max)
+}
+
+// ParseScoreRange parses a difficulty string which can be:
+// - A single score: "3.2"
+// - A category: "hard"
+// - A range of scores and/or categories: "2.5:4.0", "medium:expert"
+// An empty string returns AnyScore.
+func ParseScoreRange(s string) (ScoreRange, error) {
+	if strings.TrimSpace(s) == "" {
+		return AnyScore, nil
+	}
+
+	parts := strings.Split(s, ":")
+	if len(parts) == 1 {
+		// Single score or category
+		parts = append(parts, parts[0])
+	} else if len(parts) != 2 {
+		return ScoreRange{}, fmt.Errorf("invalid difficulty format: %s (use format like '3.2', '2.5:4.0' or 'hard')", s)
+	}
+
+	var r ScoreRange
+	var err error
+	if r.Min, _, err = parseScoreBound(parts[0], false); err != nil {
+		return ScoreRange{}, fmt.Errorf("invalid difficulty min: %w", err)
+	}
+	if r.Max, r.MaxExclusive, err = parseScoreBound(parts[1], true); err != nil {
+		return ScoreRange{}, fmt.Errorf("invalid difficulty max: %w", err)
+	}
+	if r.Min > r.Max {
+		return ScoreRange{}, fmt.Errorf("difficulty min (%.1f) cannot be greater than max (%.1f)", r.Min, r.Max)
+	}
+	return r, nil
+}
+
+// parseScoreBound parses one end of a score range: either a score between 0
+// and TrialScore or a category name. For an upper bound a category stands for
+// its exclusive maximum, for a lower bound its minimum.
+func parseScoreBound(s string, upper bool) (score float64, exclusive bool, err error) {
+	s = strings.TrimSpace(s)
+	if val, err := strconv.ParseFloat(s, 64); err == nil {
+		if val < 0 || val > TrialScore {
+			return 0, false, fmt.Errorf("score %.1f must be between 0 and %.1f", val, TrialScore)
+		}
+		return val, false, nil
+	}
+
+	category, err := ParseCategory(s)
+	if err != nil {
+		return 0, false, err
+	}
+	min, max := category.Range()
+	if !upper {
+		return min, false, nil
+	}
+	// The top category includes the top of the scale.
+	return max, category != Diabolical, nil
+}
+
+// Rating describes how hard a puzzle is for a human solver.
+type Rating struct {
+	// Score is the puzzle's rating on the SE-like scale documented above.
+	Score float64
+	// Hardest is the hardest technique the puzzle needed. When Solved is
+	// false the puzzle needed more than any technique could provide.
+	Hardest Technique
+	// Steps is the number of logical steps taken.
+	Steps int
+	// Category is the label the Score falls into.
+	Category Category
+	// Solved reports whether named techniques alone were enough.
+	Solved bool
+}
+
+// String returns the rating as "<Category> (<Score>)", e.g. "Hard (3.8)".
+func (r Rating) String() string {
+	return fmt.Sprintf("%s (%.1f)", r.Category, r.Score)
+}
+
+// Rate grades a puzzle by solving it with the LogicalSolver.
+// Returns ErrInvalidPuzzle or ErrNoSolution for broken puzzles.
+func Rate(b *board.Board) (Rating, error) {
+	return RateContext(context.Background(), b)
+}
+
+// RateContext is like Rate but stops when ctx is done, returning ctx.Err().
+func RateContext(ctx context.Context, b *board.Board) (Rating, error) {
+	result, err := SolveLogicalContext(ctx, b)
+	if err != nil {
+		return Rating{}, err
+	}
+	return RateResult(result), nil
+}
+
+// RateResult grades a puzzle from the result of its logical solve, for
+// callers that also need the steps.
+func RateResult(result *LogicalResult) Rating {
+	r := Rating{Steps: len(result.Steps), Solved: result.Solved}
+	for _, step := range result.Steps {
+		if score := step.Score(); score > r.Score {
+			r.Score = score
+		}
+		if step.Technique > r.Hardest {
+			r.Hardest = step.Technique
+		}
+	}
+	if !result.Solved {
+		r.Score = TrialScore
 	}
-	return score
+	r.Category = CategoryOf(r.Score)
+	return r
 }
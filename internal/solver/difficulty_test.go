@@ -0,0 +1,40 @@
+package solver
+
+import (
+	"testing"
+
+	"github.com/rybkr/sudoku/internal/board"
+)
+
+// referencePuzzles are the reference puzzles documented with TrialScore.
+var referencePuzzles = []struct {
+	score    float64
+	category Category
+	puzzle   string
+}{
+	{1.2, Easy, "..4.9.......7..6..1.....5.2.96.5..2432.84..6...7..635.63.1..4..7....2..3.5...8..6"},
+	{1.5, Easy, "837.5..9...5..43.7...............2.......85..15.2...8....8..9.1.7.6.2...4.2......"},
+	{2.3, Medium, "......1.93.5...7...9...6.3..39.82..7.5..7..9.8..5.......6..587.54..2..6..1.4....."},
+	{2.8, Medium, "54....12....29...8........348.3...6......84.9.7..1.....97..2........4...1.....37."},
+	{3.2, Hard, "..5.........6...91.9.23..86.8.3...5..1...6...4........9..............3.42.3..471."},
+	{4.0, Hard, ".7....9..3..5..7...86.3.....9........476..8.26......1.8..1.5...........9..9.7..34"},
+	{4.2, Expert, ".73...5.....3..16....94...79..15.4..7.5............7.......8.3.....2.97.698.3...."},
+	{5.0, Expert, "..43.9.7......6.197.......5.38.9.......1...6...2.......4..5...3.....1.57..7...2.."},
+	{7.0, Diabolical, ".63.....15.....9..978.........43....13..5274...57..6..2...43...7.4.8...5.....5.6."},
+}
+
+func TestRateReferencePuzzles(t *testing.T) {
+	for _, ref := range referencePuzzles {
+		b, err := board.NewFromString(ref.puzzle, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", ref.puzzle, err)
+		}
+		r, err := Rate(b)
+		if err != nil {
+			t.Fatalf("%s: %v", ref.puzzle, err)
+		}
+		if r.Score != ref.score || r.Category != ref.category {
+			t.Errorf("%s: rated %v, want %s (%.1f)", ref.puzzle, r, ref.category, ref.score)
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+
+	"github.com/rybkr/sudoku/internal/board"
+)
+
+// Exact-cover matrix dimensions for a 9×9 Sudoku.
+// Each of the 729 rows places one digit in one cell; it covers four of the
+// 324 columns: its cell, and the digit in its row, column and region.
+const (
+	dlxColumns = 4 * board.CellCount
+	dlxRows    = 9 * board.CellCount
+
+	// dlxCheckInterval is how many search nodes are visited between context checks.
+	dlxCheckInterval = 1024
+)
+
+// dlx is an exact-cover matrix solved with Knuth's Algorithm X using
+// dancing links. Nodes live in parallel slices rather than as pointers:
+// index 0 is the root, 1..dlxColumns are column headers and data nodes follow.
+type dlx struct {
+	left, right, up, down []int32
+	column                []int32
+	rowID                 []int16 // (pos*9 + digit-1) for data nodes, -1 for headers
+	size                  [dlxColumns + 1]int
+
+	givens *board.Board
+	stack  []int16 // rows of the partial solution being searched
+	rng    *rand.Rand
+
+	ctx      context.Context
+	nodes    int
+	maxNodes int // maxNodes stops the search with ErrNodeLimit (0 = no limit)
+	err      error
+}
+
+// newDLX builds the exact-cover matrix for a board. Constraints already
+// satisfied by the givens are left out, and only rows for digits that are
+// still candidates are added, so the matrix shrinks with every clue.
+// The layout's region map determines the region constraints, so jigsaw
+// boards are handled the same as standard ones.
+func newDLX(b *board.Board, rng *rand.Rand) *dlx {
+	d := &dlx{givens: b, rng: rng}
+
+	// Mark satisfied constraints.
+	var satisfied [dlxColumns + 1]bool
+	regions := &b.Layout().PosToRegion
+	for pos := range board.CellCount {
+		if val := b.Get(pos); val != board.EmptyCell {
+			for _, c := range dlxRowColumns(pos, val, regions) {
+				satisfied[c] = true
+			}
+		}
+	}
+
+	// Root and column headers.
+	n := dlxColumns + 1
+	capacity := n + 4*dlxRows
+	d.left = make([]int32, n, capacity)
+	d.right = make([]int32, n, capacity)
+	d.up = make([]int32, n, capacity)
+	d.down = make([]int32, n, capacity)
+	d.column = make([]int32, n, capacity)
+	d.rowID = make([]int16, n, capacity)
+	prev := int32(0)
+	for c := int32(1); c < int32(n); c++ {
+		d.up[c], d.down[c], d.column[c], d.rowID[c] = c, c, c, -1
+		if satisfied[c] {
+			// Keep the header self-linked so it never appears in the search.
+			d.left[c], d.right[c] = c, c
+			continue
+		}
+		d.left[c], d.right[prev] = prev, c
+		prev = c
+	}
+	d.right[prev], d.left[0] = 0, prev
+	d.rowID[0] = -1
+
+	// One row per remaining candidate.
+	for pos := range board.CellCount {
+		if b.Get(pos) != board.EmptyCell {
+			continue
+		}
+		mask := b.GetCandidatesMask(pos)
+		for digit := 1; digit <= 9; digit++ {
+			if mask&digitMask(digit) != 0 {
+				d.addRow(int16(pos*9+digit-1), dlxRowColumns(pos, digit, regions))
+			}
+		}
+	}
+
+	return d
+}
+
+// dlxRowColumns returns the four columns (1-based) covered by placing digit at pos.
+func dlxRowColumns(pos, digit int, regions *[board.CellCount]int) [4]int32 {
+	row, col, region := pos/9, pos%9, regions[pos]
+	return [4]int32{
+		int32(1 + pos),
+		int32(1 + board.CellCount + row*9 + digit - 1),
+		int32(1 + 2*board.CellCount + col*9 + digit - 1),
+		int32(1 + 3*board.CellCount + region*9 + digit - 1),
+	}
+}
+
+// addRow appends a row of four nodes, one per column, linked left-to-right.
+func (d *dlx) addRow(id int16, cols [4]int32) {
+	first := int32(len(d.left))
+	for i, c := range cols {
+		node := first + int32(i)
+		d.left = append(d.left, first+int32((i+3)%4))
+		d.right = append(d.right, first+int32((i+1)%4))
+		d.up = append(d.up, d.up[c])
+		d.down = append(d.down, c)
+		d.column = append(d.column, c)
+		d.rowID = append(d.rowID, id)
+		d.down[d.up[c]] = node
+		d.up[c] = node
+		d.size[c]++
+	}
+}
+
+// cover removes column c and every row that intersects it.
+func (d *dlx) cover(c int32) {
+	d.right[d.left[c]] = d.right[c]
+	d.left[d.right[c]] = d.left[c]
+	for i := d.down[c]; i != c; i = d.down[i] {
+		for j := d.right[i]; j != i; j = d.right[j] {
+			d.down[d.up[j]] = d.down[j]
+			d.up[d.down[j]] = d.up[j]
+			d.size[d.column[j]]--
+		}
+	}
+}
+
+// uncover restores column c, undoing cover in exactly the reverse order.
+func (d *dlx) uncover(c int32) {
+	for i := d.up[c]; i != c; i = d.up[i] {
+		for j := d.left[i]; j != i; j = d.left[j] {
+			d.size[d.column[j]]++
+			d.down[d.up[j]] = j
+			d.up[d.down[j]] = j
+		}
+	}
+	d.right[d.left[c]] = c
+	d.left[d.right[c]] = c
+}
+
+// search runs Algorithm X, calling visit for every exact cover found.
+// visit returns false to stop the search. search returns false once the
+// search has been stopped by visit or by the context.
+func (d *dlx) search(visit func() bool) bool {
+	d.nodes++
+	if d.maxNodes > 0 && d.nodes > d.maxNodes {
+		d.err = ErrNodeLimit
+		return false
+	}
+	if d.nodes%dlxCheckInterval == 1 && d.ctx != nil {
+		if err := d.ctx.Err(); err != nil {
+			d.err = err
+			return false
+		}
+	}
+
+	if d.right[0] == 0 {
+		return visit()
+	}
+
+	// Choose the column with the fewest rows (Knuth's S heuristic).
+	c, best := int32(0), dlxRows+1
+	for j := d.right[0]; j != 0; j = d.right[j] {
+		if d.size[j] < best {
+			c, best = j, d.size[j]
+			if best <= 1 {
+				break
+			}
+		}
+	}
+	if best == 0 {
+		return true
+	}
+
+	rows := make([]int32, 0, best)
+	for r := d.down[c]; r != c; r = d.down[r] {
+		rows = append(rows, r)
+	}
+	if d.rng != nil {
+		d.rng.Shuffle(len(rows), func(i, j int) {
+			rows[i], rows[j] = rows[j], rows[i]
+		})
+	}
+
+	d.cover(c)
+	keepGoing := true
+	for _, r := range rows {
+		d.stack = append(d.stack, d.rowID[r])
+		for j := d.right[r]; j != r; j = d.right[j] {
+			d.cover(d.column[j])
+		}
+		keepGoing = d.search(visit)
+		for j := d.left[r]; j != r; j = d.left[j] {
+			d.uncover(d.column[j])
+		}
+		d.stack = d.stack[:len(d.stack)-1]
+		if !keepGoing {
+			break
+		}
+	}
+	d.uncover(c)
+	return keepGoing
+}
+
+// board returns the givens with the current partial solution filled in.
+func (d *dlx) board() *board.Board {
+	b := d.givens.Clone()
+	for _, id := range d.stack {
+		b.SetForce(int(id)/9, int(id)%9+1)
+	}
+	return b
+}
+
+// first returns the first solution found, or nil if there is none.
+func (d *dlx) first(ctx context.Context) (*board.Board, error) {
+	var solution *board.Board
+	d.each(ctx, func(b *board.Board) bool {
+		solution = b
+		return false
+	})
+	return solution, d.err
+}
+
+// count counts solutions, stopping once limit have been found (0 = no limit).
+func (d *dlx) count(ctx context.Context, limit int) (int, error) {
+	n := 0
+	d.ctx, d.err = ctx, nil
+	d.search(func() bool {
+		n++
+		return limit <= 0 || n < limit
+	})
+	return n, d.err
+}
+
+// each enumerates every solution until fn returns false.
+func (d *dlx) each(ctx context.Context, fn func(*board.Board) bool) error {
+	d.ctx, d.err = ctx, nil
+	d.search(func() bool {
+		return fn(d.board())
+	})
+	return d.err
+}
+
+// solveDLX solves the board with the dancing-links engine.
+func (s *Solver) solveDLX(ctx context.Context) (*board.Board, error) {
+	var rng *rand.Rand
+	if s.options.Randomize {
+		rng = s.rng
+	}
+
+	d := newDLX(s.Board, rng)
+	d.maxNodes = s.options.MaxNodes
+	solution, err := d.first(ctx)
+	if errors.Is(err, ErrNodeLimit) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, contextErr(ctx)
+	}
+	if solution == nil {
+		return nil, ErrNoSolution
+	}
+	s.Board = solution
+	return solution, nil
+}
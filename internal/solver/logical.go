@@ -0,0 +1,442 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"math/bits"
+	"strings"
+
+	"github.com/rybkr/sudoku/internal/board"
+)
+
+// Technique identifies a human solving technique used by the LogicalSolver.
+// Techniques are declared in the order the solver tries them, which is also
+// their approximate order of difficulty.
+type Technique int
+
+const (
+	HiddenSingle Technique = iota
+	NakedSingle
+	PointingCandidates
+	ClaimingCandidates
+	NakedPair
+	XWing
+	HiddenPair
+	NakedTriple
+	Swordfish
+	HiddenTriple
+	Skyscraper
+	TwoStringKite
+	XYWing
+	XYZWing
+	WWing
+	SimpleColoring
+	NakedQuad
+	Jellyfish
+	HiddenQuad
+
+	// techniqueCount is the number of techniques; it must stay last.
+	techniqueCount
+)
+
+var techniqueNames = [techniqueCount]string{
+	HiddenSingle:       "Hidden Single",
+	NakedSingle:        "Naked Single",
+	PointingCandidates: "Pointing Candidates",
+	ClaimingCandidates: "Claiming Candidates",
+	NakedPair:          "Naked Pair",
+	XWing:              "X-Wing",
+	HiddenPair:         "Hidden Pair",
+	NakedTriple:        "Naked Triple",
+	Swordfish:          "Swordfish",
+	HiddenTriple:       "Hidden Triple",
+	Skyscraper:         "Skyscraper",
+	TwoStringKite:      "2-String Kite",
+	XYWing:             "XY-Wing",
+	XYZWing:            "XYZ-Wing",
+	WWing:              "W-Wing",
+	SimpleColoring:     "Simple Coloring",
+	NakedQuad:          "Naked Quad",
+	Jellyfish:          "Jellyfish",
+	HiddenQuad:         "Hidden Quad",
+}
+
+// String returns the conventional name of the technique.
+func (t Technique) String() string {
+	if t < 0 || t >= techniqueCount {
+		return fmt.Sprintf("Technique(%d)", int(t))
+	}
+	return techniqueNames[t]
+}
+
+// UnitKind distinguishes the three kinds of Sudoku units.
+type UnitKind int
+
+const (
+	Row UnitKind = iota
+	Column
+	Region
+)
+
+// Unit identifies a row, column or region by its 0-based index.
+type Unit struct {
+	Kind  UnitKind
+	Index int
+}
+
+// String returns a 1-based, human-readable name such as "row 3".
+func (u Unit) String() string {
+	switch u.Kind {
+	case Row:
+		return fmt.Sprintf("row %d", u.Index+1)
+	case Column:
+		return fmt.Sprintf("column %d", u.Index+1)
+	default:
+		return fmt.Sprintf("region %d", u.Index+1)
+	}
+}
+
+// Candidate is a digit at a cell position, used for placements and eliminations.
+type Candidate struct {
+	Pos   int
+	Digit int
+}
+
+// String returns the candidate in r<row>c<col>=<digit> notation (1-based).
+func (c Candidate) String() string {
+	return fmt.Sprintf("%s=%d", cellName(c.Pos), c.Digit)
+}
+
+// Step is a single deduction made by the LogicalSolver.
+type Step struct {
+	Technique Technique
+	// Digits lists the digits the pattern is built on.
+	Digits []int
+	// Cells lists the cells that form the pattern (not the affected cells).
+	Cells []int
+	// Units lists the units the pattern lives in, if any.
+	Units []Unit
+	// Placements are the digits this step places.
+	Placements []Candidate
+	// Eliminations are the candidates this step removes.
+	Eliminations []Candidate
+}
+
+// String returns a one-line human-readable explanation of the step.
+func (s Step) String() string {
+	var sb strings.Builder
+	sb.WriteString(s.Technique.String())
+	if len(s.Digits) > 0 {
+		digits := make([]string, len(s.Digits))
+		for i, d := range s.Digits {
+			digits[i] = fmt.Sprint(d)
+		}
+		fmt.Fprintf(&sb, " {%s}", strings.Join(digits, ","))
+	}
+	if len(s.Units) > 0 {
+		units := make([]string, len(s.Units))
+		for i, u := range s.Units {
+			units[i] = u.String()
+		}
+		fmt.Fprintf(&sb, " in %s", strings.Join(units, ", "))
+	}
+	if len(s.Cells) > 0 {
+		cells := make([]string, len(s.Cells))
+		for i, pos := range s.Cells {
+			cells[i] = cellName(pos)
+		}
+		fmt.Fprintf(&sb, " at %s", strings.Join(cells, " "))
+	}
+	for _, p := range s.Placements {
+		fmt.Fprintf(&sb, ": %s", p)
+	}
+	if len(s.Eliminations) > 0 {
+		elims := make([]string, len(s.Eliminations))
+		for i, e := range s.Eliminations {
+			elims[i] = fmt.Sprintf("%s<>%d", cellName(e.Pos), e.Digit)
+		}
+		fmt.Fprintf(&sb, ": %s", strings.Join(elims, ", "))
+	}
+	return sb.String()
+}
+
+// cellName returns the 1-based r<row>c<col> name of a position.
+func cellName(pos int) string {
+	return fmt.Sprintf("r%dc%d", pos/9+1, pos%9+1)
+}
+
+// LogicalResult is the outcome of a logical solve.
+type LogicalResult struct {
+	// Board holds every digit placed; it is complete only when Solved is true.
+	Board *board.Board
+	// Steps lists the deductions in the order they were made.
+	Steps []Step
+	// Solved reports whether the techniques were sufficient to finish the puzzle.
+	Solved bool
+}
+
+// LogicalSolver solves puzzles the way a human would: it keeps pencil-mark
+// candidates for every empty cell and applies named techniques, easiest
+// first, until the puzzle is solved or no technique makes progress.
+// It never guesses. Units are enumerated through the board's Layout, so
+// jigsaw regions are supported by every technique.
+type LogicalSolver struct {
+	Board *board.Board
+
+	// cands holds the remaining candidates of each empty cell, using the same
+	// bit layout as the board masks (bit i represents digit i+1).
+	cands [board.CellCount]uint
+
+	// units lists the cells of all 27 units: rows 0–8, columns 9–17 and
+	// regions 18–26.
+	units [27][9]int
+
+	steps []Step
+}
+
+// NewLogical creates a logical solver for the given board.
+// The board is cloned; the caller's board is never modified.
+func NewLogical(b *board.Board) *LogicalSolver {
+	ls := &LogicalSolver{Board: b.Clone()}
+
+	for i := range 9 {
+		for j := range 9 {
+			ls.units[i][j] = board.MakePos(i, j)
+			ls.units[9+i][j] = board.MakePos(j, i)
+		}
+		ls.units[18+i] = b.RegionCells(i)
+	}
+
+	for pos := range board.CellCount {
+		if ls.Board.Get(pos) == board.EmptyCell {
+			ls.cands[pos] = ls.Board.GetCandidatesMask(pos)
+		}
+	}
+
+	return ls
+}
+
+// SolveLogical is a convenience function that runs a LogicalSolver to completion.
+func SolveLogical(b *board.Board) (*LogicalResult, error) {
+	return NewLogical(b).Solve()
+}
+
+// SolveLogicalContext is like SolveLogical but stops when ctx is done.
+func SolveLogicalContext(ctx context.Context, b *board.Board) (*LogicalResult, error) {
+	return NewLogical(b).SolveContext(ctx)
+}
+
+// Solve applies techniques until the puzzle is solved or stuck.
+// Returns ErrInvalidPuzzle for boards that break Sudoku rules and
+// ErrNoSolution when the deductions reach a contradiction.
+func (ls *LogicalSolver) Solve() (*LogicalResult, error) {
+	return ls.SolveContext(context.Background())
+}
+
+// SolveContext is like Solve but stops when ctx is done, returning
+// ctx.Err(). The context is checked between techniques.
+func (ls *LogicalSolver) SolveContext(ctx context.Context) (*LogicalResult, error) {
+	if !ls.Board.IsValid() {
+		return nil, ErrInvalidPuzzle
+	}
+
+	for ls.Board.EmptyCount() > 0 {
+		if ls.hasContradiction() {
+			return nil, ErrNoSolution
+		}
+		step, ok, err := ls.NextContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		ls.Apply(step)
+	}
+
+	return &LogicalResult{
+		Board:  ls.Board,
+		Steps:  ls.steps,
+		Solved: ls.Board.EmptyCount() == 0,
+	}, nil
+}
+
+// Steps returns the deductions applied so far.
+func (ls *LogicalSolver) Steps() []Step {
+	return ls.steps
+}
+
+// Candidates returns the remaining candidate mask for a position.
+// Filled cells have no candidates.
+func (ls *LogicalSolver) Candidates(pos int) uint {
+	return ls.cands[pos]
+}
+
+// Next finds the easiest available deduction without applying it.
+// Returns false when no technique makes progress.
+func (ls *LogicalSolver) Next() (Step, bool) {
+	step, ok, _ := ls.NextContext(context.Background())
+	return step, ok
+}
+
+// NextContext is like Next but stops when ctx is done, returning ctx.Err().
+func (ls *LogicalSolver) NextContext(ctx context.Context) (Step, bool, error) {
+	for _, find := range ls.finders() {
+		if err := ctx.Err(); err != nil {
+			return Step{}, false, err
+		}
+		if step, ok := find(); ok {
+			return step, true, nil
+		}
+	}
+	return Step{}, false, nil
+}
+
+// finders returns the technique search functions in order of difficulty.
+func (ls *LogicalSolver) finders() []func() (Step, bool) {
+	return []func() (Step, bool){
+		ls.findHiddenSingle,
+		ls.findNakedSingle,
+		ls.findPointing,
+		ls.findClaiming,
+		func() (Step, bool) { return ls.findNakedSubset(2, NakedPair) },
+		func() (Step, bool) { return ls.findFish(2, XWing) },
+		func() (Step, bool) { return ls.findHiddenSubset(2, HiddenPair) },
+		func() (Step, bool) { return ls.findNakedSubset(3, NakedTriple) },
+		func() (Step, bool) { return ls.findFish(3, Swordfish) },
+		func() (Step, bool) { return ls.findHiddenSubset(3, HiddenTriple) },
+		ls.findSkyscraper,
+		ls.findTwoStringKite,
+		ls.findXYWing,
+		ls.findXYZWing,
+		ls.findWWing,
+		ls.findSimpleColoring,
+		func() (Step, bool) { return ls.findNakedSubset(4, NakedQuad) },
+		func() (Step, bool) { return ls.findFish(4, Jellyfish) },
+		func() (Step, bool) { return ls.findHiddenSubset(4, HiddenQuad) },
+	}
+}
+
+// Apply performs a step's placements and eliminations and records it.
+func (ls *LogicalSolver) Apply(step Step) {
+	for _, p := range step.Placements {
+		ls.place(p.Pos, p.Digit)
+	}
+	for _, e := range step.Eliminations {
+		ls.cands[e.Pos] &^= digitMask(e.Digit)
+	}
+	ls.steps = append(ls.steps, step)
+}
+
+// place sets a digit on the board and removes it from the candidates of
+// every cell that shares a unit with pos.
+func (ls *LogicalSolver) place(pos, digit int) {
+	ls.Board.SetForce(pos, digit)
+	ls.cands[pos] = 0
+	mask := digitMask(digit)
+	for _, u := range ls.cellUnits(pos) {
+		for _, p := range ls.units[u] {
+			ls.cands[p] &^= mask
+		}
+	}
+}
+
+// hasContradiction reports whether an empty cell has run out of candidates.
+func (ls *LogicalSolver) hasContradiction() bool {
+	for pos := range board.CellCount {
+		if ls.Board.Get(pos) == board.EmptyCell && ls.cands[pos] == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// cellUnits returns the indices into ls.units of the row, column and region
+// containing pos.
+func (ls *LogicalSolver) cellUnits(pos int) [3]int {
+	return [3]int{pos / 9, 9 + pos%9, 18 + ls.Board.Layout().PosToRegion[pos]}
+}
+
+// unitInfo converts an index into ls.units to a public Unit.
+func unitInfo(u int) Unit {
+	return Unit{Kind: UnitKind(u / 9), Index: u % 9}
+}
+
+// sees reports whether two distinct cells share a row, column or region.
+func (ls *LogicalSolver) sees(a, b int) bool {
+	if a == b {
+		return false
+	}
+	regions := &ls.Board.Layout().PosToRegion
+	return a/9 == b/9 || a%9 == b%9 || regions[a] == regions[b]
+}
+
+// cellsWith returns the cells of unit u that still hold digit as a candidate.
+func (ls *LogicalSolver) cellsWith(u, digit int) []int {
+	mask := digitMask(digit)
+	var cells []int
+	for _, pos := range ls.units[u] {
+		if ls.cands[pos]&mask != 0 {
+			cells = append(cells, pos)
+		}
+	}
+	return cells
+}
+
+// eliminateSeen collects eliminations of digit from every cell that sees all
+// of the given cells, skipping the cells themselves.
+func (ls *LogicalSolver) eliminateSeen(digit int, cells ...int) []Candidate {
+	mask := digitMask(digit)
+	var elims []Candidate
+	for pos := range board.CellCount {
+		if ls.cands[pos]&mask == 0 {
+			continue
+		}
+		seesAll := true
+		for _, c := range cells {
+			if !ls.sees(pos, c) {
+				seesAll = false
+				break
+			}
+		}
+		if seesAll {
+			elims = append(elims, Candidate{Pos: pos, Digit: digit})
+		}
+	}
+	return elims
+}
+
+// digitMask returns the candidate bit for a digit 1–9.
+func digitMask(digit int) uint {
+	return 1 << (digit - 1)
+}
+
+// maskDigits returns the digits set in a candidate mask in ascending order.
+func maskDigits(mask uint) []int {
+	digits := make([]int, 0, bits.OnesCount(mask))
+	for mask != 0 {
+		digits = append(digits, bits.TrailingZeros(mask)+1)
+		mask &= mask - 1
+	}
+	return digits
+}
+
+// combinations calls fn with every k-element subset of items, in
+// lexicographic order, until fn returns true.
+func combinations(items []int, k int, fn func([]int) bool) bool {
+	combo := make([]int, k)
+	var rec func(start, depth int) bool
+	rec = func(start, depth int) bool {
+		if depth == k {
+			return fn(combo)
+		}
+		for i := start; i <= len(items)-(k-depth); i++ {
+			combo[depth] = items[i]
+			if rec(i+1, depth+1) {
+				return true
+			}
+		}
+		return false
+	}
+	return rec(0, 0)
+}
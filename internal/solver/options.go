@@ -2,15 +2,33 @@ package solver
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
+// Engine selects the search algorithm used by the solver.
+type Engine int
+
+const (
+	// Backtracking applies constraint propagation and MRV backtracking.
+	Backtracking Engine = iota
+	// DancingLinks runs Knuth's Algorithm X over an exact-cover matrix.
+	DancingLinks
+)
+
 // Options configures the solver behavior.
 type Options struct {
-	MaxSolutions int             // MaxSolutions limits solution search (0 = unlimited)
+	MaxSolutions int             // MaxSolutions limits solution search (0 = unlimited); Solve checks uniqueness unless 1
 	Timeout      time.Duration   // Timeout limits solving time
 	Randomize    bool            // Randomize solution selection for puzzle generation
+	Seed         int64           // Seed for reproducible randomization (0 = random)
 	Context      context.Context // Context for cancellation
+	Engine       Engine          // Engine selects the search algorithm
+	// MaxNodes caps the search nodes the DancingLinks engine visits looking
+	// for a single solution (0 = unlimited). Unlike Timeout, the limit is
+	// hit at the same point on every machine, so a randomized search that
+	// gives up with ErrNodeLimit can be restarted reproducibly.
+	MaxNodes int
 }
 
 // DefaultOptions returns standard solver options.
@@ -31,16 +49,26 @@ func GenerateOptions() *Options {
 	}
 }
 
-// makeContext creates a context with timeout if specified.
-func (s *Solver) makeContext() (context.Context, context.CancelFunc) {
-	ctx := s.options.Context
-	if ctx == nil {
-		ctx = context.Background()
+// makeContext derives the solver's working context from parent, which may be
+// nil, applying Timeout if specified. Expiry of Timeout is recorded as the
+// cause ErrTimeout so that contextErr can tell it apart from the parent's.
+func (s *Solver) makeContext(parent context.Context) (context.Context, context.CancelFunc) {
+	if parent == nil {
+		parent = context.Background()
 	}
 
 	if s.options.Timeout > 0 {
-		return context.WithTimeout(ctx, s.options.Timeout)
+		return context.WithTimeoutCause(parent, s.options.Timeout, ErrTimeout)
 	}
 
-	return context.WithCancel(ctx)
+	return context.WithCancel(parent)
+}
+
+// contextErr returns the error to report for a done context: ErrTimeout when
+// the solver's own Timeout expired, otherwise ctx.Err().
+func contextErr(ctx context.Context) error {
+	if errors.Is(context.Cause(ctx), ErrTimeout) {
+		return ErrTimeout
+	}
+	return ctx.Err()
 }
@@ -0,0 +1,407 @@
+package solver
+
+import (
+	"math/bits"
+	"slices"
+
+	"github.com/rybkr/sudoku/internal/board"
+)
+
+// strongLink is a pair of cells that are the only two places for a digit in a unit.
+type strongLink struct {
+	unit int
+	a, b int
+}
+
+// strongLinks returns every strong link for digit among units [from, to).
+func (ls *LogicalSolver) strongLinks(digit, from, to int) []strongLink {
+	var links []strongLink
+	for u := from; u < to; u++ {
+		if cells := ls.cellsWith(u, digit); len(cells) == 2 {
+			links = append(links, strongLink{unit: u, a: cells[0], b: cells[1]})
+		}
+	}
+	return links
+}
+
+// findFish finds an X-Wing (n=2), Swordfish (n=3) or Jellyfish (n=4): n base
+// lines whose candidates for a digit all fall in the same n cross lines.
+// The digit is then removed from the rest of those cross lines.
+func (ls *LogicalSolver) findFish(n int, technique Technique) (Step, bool) {
+	for digit := 1; digit <= 9; digit++ {
+		mask := digitMask(digit)
+		// base 0 searches rows against columns, base 9 columns against rows.
+		for _, base := range []int{0, 9} {
+			cover := 9 - base
+
+			// spans[i] is a bitmask of the cross lines holding digit in base line i.
+			var spans [9]uint
+			var lines []int
+			for i := range 9 {
+				for j, pos := range ls.units[base+i] {
+					if ls.cands[pos]&mask != 0 {
+						spans[i] |= 1 << j
+					}
+				}
+				if c := bits.OnesCount(spans[i]); c >= 2 && c <= n {
+					lines = append(lines, i)
+				}
+			}
+			if len(lines) < n {
+				continue
+			}
+
+			var step Step
+			found := combinations(lines, n, func(combo []int) bool {
+				var union uint
+				for _, i := range combo {
+					union |= spans[i]
+				}
+				if bits.OnesCount(union) != n {
+					return false
+				}
+
+				var cells []int
+				var units []Unit
+				for _, i := range combo {
+					units = append(units, unitInfo(base+i))
+					for j, pos := range ls.units[base+i] {
+						if union&(1<<j) != 0 && ls.cands[pos]&mask != 0 {
+							cells = append(cells, pos)
+						}
+					}
+				}
+
+				var elims []Candidate
+				for _, j := range maskDigits(union) {
+					units = append(units, unitInfo(cover+j-1))
+					for i, pos := range ls.units[cover+j-1] {
+						if ls.cands[pos]&mask != 0 && !slices.Contains(combo, i) {
+							elims = append(elims, Candidate{Pos: pos, Digit: digit})
+						}
+					}
+				}
+				if len(elims) == 0 {
+					return false
+				}
+
+				slices.Sort(cells)
+				step = Step{
+					Technique:    technique,
+					Digits:       []int{digit},
+					Cells:        cells,
+					Units:        units,
+					Eliminations: elims,
+				}
+				return true
+			})
+			if found {
+				return step, true
+			}
+		}
+	}
+	return Step{}, false
+}
+
+// findSkyscraper finds two parallel strong links for a digit whose ends meet
+// in one cross line. One of the two far ends ("roofs") must hold the digit,
+// so it is removed from every cell that sees both roofs.
+func (ls *LogicalSolver) findSkyscraper() (Step, bool) {
+	for digit := 1; digit <= 9; digit++ {
+		for _, base := range []int{0, 9} {
+			// line returns the cross-line coordinate of a cell for this orientation.
+			line := func(pos int) int {
+				if base == 0 {
+					return pos % 9
+				}
+				return pos / 9
+			}
+
+			links := ls.strongLinks(digit, base, base+9)
+			for i, l1 := range links {
+				for _, l2 := range links[i+1:] {
+					for _, ends := range [][4]int{
+						{l1.a, l1.b, l2.a, l2.b},
+						{l1.a, l1.b, l2.b, l2.a},
+						{l1.b, l1.a, l2.a, l2.b},
+						{l1.b, l1.a, l2.b, l2.a},
+					} {
+						base1, roof1, base2, roof2 := ends[0], ends[1], ends[2], ends[3]
+						if line(base1) != line(base2) || line(roof1) == line(roof2) {
+							continue
+						}
+						elims := ls.eliminateSeen(digit, roof1, roof2)
+						if len(elims) == 0 {
+							continue
+						}
+						return Step{
+							Technique:    Skyscraper,
+							Digits:       []int{digit},
+							Cells:        []int{roof1, base1, base2, roof2},
+							Units:        []Unit{unitInfo(l1.unit), unitInfo(l2.unit)},
+							Eliminations: elims,
+						}, true
+					}
+				}
+			}
+		}
+	}
+	return Step{}, false
+}
+
+// findTwoStringKite finds a row strong link and a column strong link for a
+// digit with one end of each in the same region. One of the two remaining
+// ends must hold the digit, so it is removed from cells that see both.
+func (ls *LogicalSolver) findTwoStringKite() (Step, bool) {
+	regions := &ls.Board.Layout().PosToRegion
+	for digit := 1; digit <= 9; digit++ {
+		rowLinks := ls.strongLinks(digit, 0, 9)
+		colLinks := ls.strongLinks(digit, 9, 18)
+		for _, rl := range rowLinks {
+			for _, cl := range colLinks {
+				for _, ends := range [][4]int{
+					{rl.a, rl.b, cl.a, cl.b},
+					{rl.a, rl.b, cl.b, cl.a},
+					{rl.b, rl.a, cl.a, cl.b},
+					{rl.b, rl.a, cl.b, cl.a},
+				} {
+					rowEnd, rowTip, colEnd, colTip := ends[0], ends[1], ends[2], ends[3]
+					region := regions[rowEnd]
+					if rowEnd == colEnd || rowTip == colTip || regions[colEnd] != region ||
+						regions[rowTip] == region || regions[colTip] == region {
+						continue
+					}
+					elims := ls.eliminateSeen(digit, rowTip, colTip)
+					if len(elims) == 0 {
+						continue
+					}
+					return Step{
+						Technique:    TwoStringKite,
+						Digits:       []int{digit},
+						Cells:        []int{rowTip, rowEnd, colEnd, colTip},
+						Units:        []Unit{unitInfo(rl.unit), unitInfo(cl.unit), unitInfo(18 + region)},
+						Eliminations: elims,
+					}, true
+				}
+			}
+		}
+	}
+	return Step{}, false
+}
+
+// findXYWing finds a bivalue pivot {x,y} that sees two bivalue pincers {x,z}
+// and {y,z}. Either pincer must be z, so z is removed from cells seeing both.
+func (ls *LogicalSolver) findXYWing() (Step, bool) {
+	for pivot := range board.CellCount {
+		pm := ls.cands[pivot]
+		if bits.OnesCount(pm) != 2 {
+			continue
+		}
+		for a := range board.CellCount {
+			am := ls.cands[a]
+			if bits.OnesCount(am) != 2 || bits.OnesCount(am&pm) != 1 || !ls.sees(pivot, a) {
+				continue
+			}
+			z := am &^ pm
+			want := (pm &^ am) | z
+			for b := a + 1; b < board.CellCount; b++ {
+				if ls.cands[b] != want || !ls.sees(pivot, b) {
+					continue
+				}
+				digit := bits.TrailingZeros(z) + 1
+				elims := ls.eliminateSeen(digit, a, b)
+				if len(elims) == 0 {
+					continue
+				}
+				return Step{
+					Technique:    XYWing,
+					Digits:       maskDigits(pm | z),
+					Cells:        []int{pivot, a, b},
+					Eliminations: elims,
+				}, true
+			}
+		}
+	}
+	return Step{}, false
+}
+
+// findXYZWing finds a trivalue pivot {x,y,z} that sees bivalue pincers {x,z}
+// and {y,z}. One of the three must be z, so z is removed from cells seeing all.
+func (ls *LogicalSolver) findXYZWing() (Step, bool) {
+	for pivot := range board.CellCount {
+		pm := ls.cands[pivot]
+		if bits.OnesCount(pm) != 3 {
+			continue
+		}
+		for a := range board.CellCount {
+			am := ls.cands[a]
+			if bits.OnesCount(am) != 2 || am&^pm != 0 || !ls.sees(pivot, a) {
+				continue
+			}
+			for b := a + 1; b < board.CellCount; b++ {
+				bm := ls.cands[b]
+				if bits.OnesCount(bm) != 2 || bm&^pm != 0 || am|bm != pm || !ls.sees(pivot, b) {
+					continue
+				}
+				digit := bits.TrailingZeros(am&bm) + 1
+				elims := ls.eliminateSeen(digit, pivot, a, b)
+				if len(elims) == 0 {
+					continue
+				}
+				return Step{
+					Technique:    XYZWing,
+					Digits:       maskDigits(pm),
+					Cells:        []int{pivot, a, b},
+					Eliminations: elims,
+				}, true
+			}
+		}
+	}
+	return Step{}, false
+}
+
+// findWWing finds two identical bivalue cells {x,y} that do not see each
+// other but are joined by a strong link on x. One of them must be y, so y is
+// removed from cells that see both.
+func (ls *LogicalSolver) findWWing() (Step, bool) {
+	for a := range board.CellCount {
+		am := ls.cands[a]
+		if bits.OnesCount(am) != 2 {
+			continue
+		}
+		for b := a + 1; b < board.CellCount; b++ {
+			if ls.cands[b] != am || ls.sees(a, b) {
+				continue
+			}
+			for _, link := range maskDigits(am) {
+				other := maskDigits(am &^ digitMask(link))[0]
+				for _, sl := range ls.strongLinks(link, 0, 27) {
+					if sl.a == a || sl.a == b || sl.b == a || sl.b == b {
+						continue
+					}
+					if !(ls.sees(sl.a, a) && ls.sees(sl.b, b)) && !(ls.sees(sl.a, b) && ls.sees(sl.b, a)) {
+						continue
+					}
+					elims := ls.eliminateSeen(other, a, b)
+					if len(elims) == 0 {
+						continue
+					}
+					return Step{
+						Technique:    WWing,
+						Digits:       maskDigits(am),
+						Cells:        []int{a, sl.a, sl.b, b},
+						Units:        []Unit{unitInfo(sl.unit)},
+						Eliminations: elims,
+					}, true
+				}
+			}
+		}
+	}
+	return Step{}, false
+}
+
+// findSimpleColoring two-colors each chain of strong links for a digit.
+// If two cells of one color see each other, that color is false everywhere
+// (color wrap); otherwise any cell seeing both colors loses the digit
+// (color trap).
+func (ls *LogicalSolver) findSimpleColoring() (Step, bool) {
+	for digit := 1; digit <= 9; digit++ {
+		mask := digitMask(digit)
+
+		var adj [board.CellCount][]int
+		for _, sl := range ls.strongLinks(digit, 0, 27) {
+			adj[sl.a] = append(adj[sl.a], sl.b)
+			adj[sl.b] = append(adj[sl.b], sl.a)
+		}
+
+		var color [board.CellCount]int // 0 = uncolored, 1 or 2 otherwise
+		for start := range board.CellCount {
+			if color[start] != 0 || len(adj[start]) == 0 {
+				continue
+			}
+
+			// Breadth-first two-coloring of the chain containing start.
+			var groups [3][]int
+			color[start] = 1
+			queue := []int{start}
+			for len(queue) > 0 {
+				pos := queue[0]
+				queue = queue[1:]
+				groups[color[pos]] = append(groups[color[pos]], pos)
+				for _, nb := range adj[pos] {
+					if color[nb] == 0 {
+						color[nb] = 3 - color[pos]
+						queue = append(queue, nb)
+					}
+				}
+			}
+			if len(groups[1])+len(groups[2]) < 4 {
+				// A lone strong link is covered by the locked-candidate techniques.
+				continue
+			}
+
+			chain := append(append([]int(nil), groups[1]...), groups[2]...)
+			slices.Sort(chain)
+
+			// Color wrap.
+			for c := 1; c <= 2; c++ {
+				if !ls.anyMutuallyVisible(groups[c]) {
+					continue
+				}
+				elims := make([]Candidate, 0, len(groups[c]))
+				for _, pos := range groups[c] {
+					elims = append(elims, Candidate{Pos: pos, Digit: digit})
+				}
+				slices.SortFunc(elims, func(x, y Candidate) int { return x.Pos - y.Pos })
+				return Step{
+					Technique:    SimpleColoring,
+					Digits:       []int{digit},
+					Cells:        chain,
+					Eliminations: elims,
+				}, true
+			}
+
+			// Color trap.
+			var elims []Candidate
+			for pos := range board.CellCount {
+				if ls.cands[pos]&mask == 0 || slices.Contains(chain, pos) {
+					continue
+				}
+				if ls.seesAny(pos, groups[1]) && ls.seesAny(pos, groups[2]) {
+					elims = append(elims, Candidate{Pos: pos, Digit: digit})
+				}
+			}
+			if len(elims) > 0 {
+				return Step{
+					Technique:    SimpleColoring,
+					Digits:       []int{digit},
+					Cells:        chain,
+					Eliminations: elims,
+				}, true
+			}
+		}
+	}
+	return Step{}, false
+}
+
+// anyMutuallyVisible reports whether any two of the cells see each other.
+func (ls *LogicalSolver) anyMutuallyVisible(cells []int) bool {
+	for i, a := range cells {
+		for _, b := range cells[i+1:] {
+			if ls.sees(a, b) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// seesAny reports whether pos sees at least one of the cells.
+func (ls *LogicalSolver) seesAny(pos int, cells []int) bool {
+	for _, c := range cells {
+		if ls.sees(pos, c) {
+			return true
+		}
+	}
+	return false
+}
@@ -15,6 +15,7 @@ var (
 	ErrMultipleSolutions = errors.New("puzzle has multiple solutions")
 	ErrInvalidPuzzle     = errors.New("puzzle violates Sudoku constraints")
 	ErrTimeout           = errors.New("solver timeout exceeded")
+	ErrNodeLimit         = errors.New("solver node limit exceeded")
 )
 
 // Solver implements algorithms for solving Sudoku puzzles.
@@ -36,7 +37,11 @@ func New(b *board.Board, options *Options) *Solver {
 	}
 
 	if options.Randomize {
-		s.rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+		seed := options.Seed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		s.rng = rand.New(rand.NewSource(seed))
 	}
 
 	return s
@@ -44,11 +49,31 @@ func New(b *board.Board, options *Options) *Solver {
 
 // Solve attempts to solve the puzzle.
 // Returns the solved board or an error if unsolvable.
+// Unless MaxSolutions is 1, Solve also verifies that the solution is unique
+// and returns ErrMultipleSolutions if it is not.
 func (s *Solver) Solve() (*board.Board, error) {
+	return s.SolveContext(s.options.Context)
+}
+
+// SolveContext is like Solve but stops as soon as ctx is done.
+// Returns ErrTimeout when Options.Timeout elapses first, or ctx.Err() when
+// ctx itself is cancelled or reaches its deadline.
+func (s *Solver) SolveContext(ctx context.Context) (*board.Board, error) {
 	if !s.Board.IsValid() {
 		return nil, ErrInvalidPuzzle
 	}
 
+	ctx, cancel := s.makeContext(ctx)
+	defer cancel()
+
+	if s.options.MaxSolutions != 1 {
+		return s.solveUnique(ctx)
+	}
+
+	if s.options.Engine == DancingLinks {
+		return s.solveDLX(ctx)
+	}
+
 	// fillThreeBoxes seeds 3 diagonal 3×3 boxes simultaneously — valid only for
 	// standard layouts where those boxes share no row, column, or region constraints.
 	if s.Board.EmptyCount() == board.CellCount && s.Board.Layout().Type == "standard" {
@@ -56,7 +81,7 @@ func (s *Solver) Solve() (*board.Board, error) {
 	}
 
 	// Constraint propagation is faster, try this first
-	if err := s.PropagateConstraints(); err != nil {
+	if err := s.propagate(ctx); err != nil {
 		return nil, err
 	}
 	if s.Board.EmptyCount() == 0 {
@@ -66,10 +91,12 @@ func (s *Solver) Solve() (*board.Board, error) {
 	// Start backtracking with MRV heuristic
 	// MRV = Minimum Remaining Values, guess on the most constrained cells first
 	// to reduce total search space
-	ctx, cancel := s.makeContext()
-	defer cancel()
-
 	if !s.backtrack(ctx) {
+		// backtrack also unwinds when the context expires, so only report
+		// ErrNoSolution once the search space has actually been exhausted.
+		if ctx.Err() != nil {
+			return nil, contextErr(ctx)
+		}
 		return nil, ErrNoSolution
 	} else {
 		return s.Board, nil
@@ -78,11 +105,19 @@ func (s *Solver) Solve() (*board.Board, error) {
 
 // PropagateConstraints applies constraint propagation techniques.
 func (s *Solver) PropagateConstraints() error {
+	return s.propagate(context.Background())
+}
+
+// propagate implements PropagateConstraints, checking ctx between passes.
+func (s *Solver) propagate(ctx context.Context) error {
 	changed := true
 	iterations := 0
 	maxIterations := board.CellCount * board.CellCount
 
 	for changed && iterations < maxIterations {
+		if ctx.Err() != nil {
+			return contextErr(ctx)
+		}
 		changed = false
 		iterations++
 
@@ -162,6 +197,9 @@ func (s *Solver) findHiddenSinglesInRow(row int) bool {
 	for val := 1; val <= 9; val++ {
 		if len(valuePossibilities[val]) == 1 {
 			pos := valuePossibilities[val][0]
+			if !s.canPlace(pos, val) {
+				continue // Another single took the cell; will surface as a contradiction
+			}
 			s.Board.SetForce(pos, val)
 			changed = true
 		}
@@ -190,6 +228,9 @@ func (s *Solver) findHiddenSinglesInCol(col int) bool {
 	for val := 1; val <= 9; val++ {
 		if len(valuePossibilities[val]) == 1 {
 			pos := valuePossibilities[val][0]
+			if !s.canPlace(pos, val) {
+				continue // Another single took the cell; will surface as a contradiction
+			}
 			s.Board.SetForce(pos, val)
 			changed = true
 		}
@@ -218,6 +259,9 @@ func (s *Solver) findHiddenSinglesInRegion(region int) bool {
 	for val := 1; val <= 9; val++ {
 		if len(valuePossibilities[val]) == 1 {
 			pos := valuePossibilities[val][0]
+			if !s.canPlace(pos, val) {
+				continue // Another single took the cell; will surface as a contradiction
+			}
 			s.Board.SetForce(pos, val)
 			changed = true
 		}
@@ -226,6 +270,13 @@ func (s *Solver) findHiddenSinglesInRegion(region int) bool {
 	return changed
 }
 
+// canPlace reports whether val is still a legal candidate for the empty cell at pos.
+// Hidden singles are collected before any are placed, so an earlier placement
+// in the same pass may have taken the cell or the digit.
+func (s *Solver) canPlace(pos, val int) bool {
+	return s.Board.Get(pos) == board.EmptyCell && s.Board.GetCandidatesMask(pos)&(1<<(val-1)) != 0
+}
+
 // hasContradiction checks if the board has reached an invalid state.
 func (s *Solver) hasContradiction() bool {
 	for pos := range board.CellCount {
@@ -244,8 +295,13 @@ func (s *Solver) backtrack(ctx context.Context) bool {
 	default:
 	}
 
+	// Propagation places digits beyond the one guessed by the caller, so keep
+	// a snapshot to fully undo this level if every branch fails.
+	saved := *s.Board
+
 	// Apply constraint propagation at each level
-	if err := s.PropagateConstraints(); err != nil {
+	if err := s.propagate(ctx); err != nil {
+		*s.Board = saved
 		return false
 	}
 
@@ -257,6 +313,7 @@ func (s *Solver) backtrack(ctx context.Context) bool {
 	// Find the cell with the minimum remaining values
 	pos, candidates := s.FindMRVCell()
 	if len(candidates) == 0 {
+		*s.Board = saved
 		return false
 	}
 
@@ -275,6 +332,7 @@ func (s *Solver) backtrack(ctx context.Context) bool {
 		s.Board.Clear(pos)
 	}
 
+	*s.Board = saved
 	return false
 }
 
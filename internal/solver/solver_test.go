@@ -0,0 +1,118 @@
+package solver
+
+import (
+	"testing"
+
+	"github.com/rybkr/sudoku/internal/board"
+)
+
+// benchPuzzle is a puzzle and, for jigsaw boards, its region map.
+type benchPuzzle struct {
+	puzzle, layout string
+}
+
+// Hard puzzles for comparing the engines.
+var (
+	standardPuzzles = []benchPuzzle{
+		{".63.....15.....9..978.........43....13..5274...57..6..2...43...7.4.8...5.....5.6.", ""},
+		{".6..851....17.9......4.....3.8..15.95..........6..4.2....59...3..3..8....1.6..79.", ""},
+		{".9.7.5.1.....2..5.6..4.....3.....7..21...4.....46.....5.......9......8..4..9..6.7", ""},
+	}
+	jigsawPuzzles = []benchPuzzle{
+		{".8.2.439.9.76.8.....6....57..34.7.8...5................3.741.2.4.........6.5.3...", "001111222000112225000112255303412555333444455333444485366777788666677888666777888"},
+		{"5....918..1..3.6....6..........82..66..9.....4.785639....4.....36................", "000001222000111222301112252333114555333444455336744455666774885666778888667777888"},
+		{".......73....42.5....5.3..7.8.....2......6.........3.13459...6.7..8.4....9.......", "000022222000022122330111155334411555334441155334444855366778888666677788666777788"},
+	}
+)
+
+// parsePuzzles builds the boards of puzzles.
+func parsePuzzles(tb testing.TB, puzzles []benchPuzzle) []*board.Board {
+	tb.Helper()
+	boards := make([]*board.Board, len(puzzles))
+	for i, p := range puzzles {
+		var layout *board.Layout
+		if p.layout != "" {
+			var err error
+			if layout, err = board.ParseLayout(p.layout); err != nil {
+				tb.Fatalf("%s: %v", p.layout, err)
+			}
+		}
+		b, err := board.NewFromString(p.puzzle, layout)
+		if err != nil {
+			tb.Fatalf("%s: %v", p.puzzle, err)
+		}
+		boards[i] = b
+	}
+	return boards
+}
+
+func benchmarkSolve(b *testing.B, engine Engine, puzzles []benchPuzzle) {
+	boards := parsePuzzles(b, puzzles)
+	opts := DefaultOptions()
+	opts.Timeout = 0
+	opts.Engine = engine
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, p := range boards {
+			if _, err := New(p.Clone(), opts).Solve(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkSolveBacktrackingStandard(b *testing.B) {
+	benchmarkSolve(b, Backtracking, standardPuzzles)
+}
+
+func BenchmarkSolveBacktrackingJigsaw(b *testing.B) {
+	benchmarkSolve(b, Backtracking, jigsawPuzzles)
+}
+
+func BenchmarkSolveDancingLinksStandard(b *testing.B) {
+	benchmarkSolve(b, DancingLinks, standardPuzzles)
+}
+
+func BenchmarkSolveDancingLinksJigsaw(b *testing.B) {
+	benchmarkSolve(b, DancingLinks, jigsawPuzzles)
+}
+
+// benchmarkCount counts up to two solutions, as the generator does to check
+// that a puzzle is unique.
+func benchmarkCount(b *testing.B, puzzles []benchPuzzle) {
+	boards := parsePuzzles(b, puzzles)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, p := range boards {
+			if n, err := CountSolutions(p, 2); err != nil || n != 1 {
+				b.Fatalf("counted %d solutions: %v", n, err)
+			}
+		}
+	}
+}
+
+func BenchmarkCountUniqueStandard(b *testing.B) {
+	benchmarkCount(b, standardPuzzles)
+}
+
+func BenchmarkCountUniqueJigsaw(b *testing.B) {
+	benchmarkCount(b, jigsawPuzzles)
+}
+
+// TestBacktrackingRestoresPropagation solves a puzzle where a failed branch
+// leaves digits placed by constraint propagation behind unless backtrack
+// restores the board; the engine then reported ErrNoSolution.
+func TestBacktrackingRestoresPropagation(t *testing.T) {
+	b := parsePuzzles(t, standardPuzzles[1:2])[0]
+	want, err := New(b.Clone(), &Options{MaxSolutions: 1, Engine: DancingLinks}).Solve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := New(b.Clone(), &Options{MaxSolutions: 1, Engine: Backtracking}).Solve()
+	if err != nil {
+		t.Fatalf("backtracking: %v", err)
+	}
+	if got.String() != want.String() {
+		t.Errorf("backtracking solved %s, want %s", got, want)
+	}
+}
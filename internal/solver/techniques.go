@@ -0,0 +1,255 @@
+package solver
+
+import (
+	"math/bits"
+	"slices"
+
+	"github.com/rybkr/sudoku/internal/board"
+)
+
+// findHiddenSingle finds a digit that fits in only one cell of a unit.
+// Regions are searched first since box singles are the easiest to spot.
+func (ls *LogicalSolver) findHiddenSingle() (Step, bool) {
+	for i := range 27 {
+		u := (i + 18) % 27
+		for digit := 1; digit <= 9; digit++ {
+			cells := ls.cellsWith(u, digit)
+			if len(cells) != 1 {
+				continue
+			}
+			return Step{
+				Technique:  HiddenSingle,
+				Digits:     []int{digit},
+				Cells:      cells,
+				Units:      []Unit{unitInfo(u)},
+				Placements: []Candidate{{Pos: cells[0], Digit: digit}},
+			}, true
+		}
+	}
+	return Step{}, false
+}
+
+// findNakedSingle finds a cell with only one remaining candidate.
+func (ls *LogicalSolver) findNakedSingle() (Step, bool) {
+	for pos := range board.CellCount {
+		if bits.OnesCount(ls.cands[pos]) != 1 {
+			continue
+		}
+		digit := bits.TrailingZeros(ls.cands[pos]) + 1
+		return Step{
+			Technique:  NakedSingle,
+			Digits:     []int{digit},
+			Cells:      []int{pos},
+			Placements: []Candidate{{Pos: pos, Digit: digit}},
+		}, true
+	}
+	return Step{}, false
+}
+
+// findPointing finds a digit whose candidates in a region all lie on one row
+// or column, which removes it from the rest of that line.
+func (ls *LogicalSolver) findPointing() (Step, bool) {
+	regions := &ls.Board.Layout().PosToRegion
+	for region := range 9 {
+		u := 18 + region
+		for digit := 1; digit <= 9; digit++ {
+			cells := ls.cellsWith(u, digit)
+			if len(cells) < 2 {
+				continue
+			}
+			for _, line := range ls.commonLines(cells) {
+				var elims []Candidate
+				for _, pos := range ls.cellsWith(line, digit) {
+					if regions[pos] != region {
+						elims = append(elims, Candidate{Pos: pos, Digit: digit})
+					}
+				}
+				if len(elims) > 0 {
+					return Step{
+						Technique:    PointingCandidates,
+						Digits:       []int{digit},
+						Cells:        cells,
+						Units:        []Unit{unitInfo(u), unitInfo(line)},
+						Eliminations: elims,
+					}, true
+				}
+			}
+		}
+	}
+	return Step{}, false
+}
+
+// findClaiming finds a digit whose candidates in a row or column all lie in
+// one region, which removes it from the rest of that region.
+func (ls *LogicalSolver) findClaiming() (Step, bool) {
+	regions := &ls.Board.Layout().PosToRegion
+	for line := range 18 {
+		for digit := 1; digit <= 9; digit++ {
+			cells := ls.cellsWith(line, digit)
+			if len(cells) < 2 {
+				continue
+			}
+			region := regions[cells[0]]
+			sameRegion := true
+			for _, pos := range cells[1:] {
+				if regions[pos] != region {
+					sameRegion = false
+					break
+				}
+			}
+			if !sameRegion {
+				continue
+			}
+
+			var elims []Candidate
+			for _, pos := range ls.cellsWith(18+region, digit) {
+				if !slices.Contains(ls.units[line][:], pos) {
+					elims = append(elims, Candidate{Pos: pos, Digit: digit})
+				}
+			}
+			if len(elims) > 0 {
+				return Step{
+					Technique:    ClaimingCandidates,
+					Digits:       []int{digit},
+					Cells:        cells,
+					Units:        []Unit{unitInfo(line), unitInfo(18 + region)},
+					Eliminations: elims,
+				}, true
+			}
+		}
+	}
+	return Step{}, false
+}
+
+// commonLines returns the row and/or column (as unit indices) shared by all cells.
+func (ls *LogicalSolver) commonLines(cells []int) []int {
+	sameRow, sameCol := true, true
+	for _, pos := range cells[1:] {
+		sameRow = sameRow && pos/9 == cells[0]/9
+		sameCol = sameCol && pos%9 == cells[0]%9
+	}
+	var lines []int
+	if sameRow {
+		lines = append(lines, cells[0]/9)
+	}
+	if sameCol {
+		lines = append(lines, 9+cells[0]%9)
+	}
+	return lines
+}
+
+// findNakedSubset finds n cells in a unit whose candidates are limited to the
+// same n digits; those digits are removed from the rest of the unit.
+func (ls *LogicalSolver) findNakedSubset(n int, technique Technique) (Step, bool) {
+	for u := range 27 {
+		var cells []int
+		for _, pos := range ls.units[u] {
+			if c := bits.OnesCount(ls.cands[pos]); c >= 2 && c <= n {
+				cells = append(cells, pos)
+			}
+		}
+		if len(cells) < n {
+			continue
+		}
+
+		var step Step
+		found := combinations(cells, n, func(combo []int) bool {
+			var union uint
+			for _, pos := range combo {
+				union |= ls.cands[pos]
+			}
+			if bits.OnesCount(union) != n {
+				return false
+			}
+
+			var elims []Candidate
+			for _, pos := range ls.units[u] {
+				if slices.Contains(combo, pos) {
+					continue
+				}
+				for _, digit := range maskDigits(ls.cands[pos] & union) {
+					elims = append(elims, Candidate{Pos: pos, Digit: digit})
+				}
+			}
+			if len(elims) == 0 {
+				return false
+			}
+
+			step = Step{
+				Technique:    technique,
+				Digits:       maskDigits(union),
+				Cells:        append([]int(nil), combo...),
+				Units:        []Unit{unitInfo(u)},
+				Eliminations: elims,
+			}
+			return true
+		})
+		if found {
+			return step, true
+		}
+	}
+	return Step{}, false
+}
+
+// findHiddenSubset finds n digits in a unit that are confined to the same n
+// cells; every other candidate is removed from those cells.
+func (ls *LogicalSolver) findHiddenSubset(n int, technique Technique) (Step, bool) {
+	for u := range 27 {
+		// places[d] is a bitmask over the unit's cell indices that hold digit d.
+		var places [10]uint
+		var digits []int
+		for digit := 1; digit <= 9; digit++ {
+			for i, pos := range ls.units[u] {
+				if ls.cands[pos]&digitMask(digit) != 0 {
+					places[digit] |= 1 << i
+				}
+			}
+			if c := bits.OnesCount(places[digit]); c >= 2 && c <= n {
+				digits = append(digits, digit)
+			}
+		}
+		if len(digits) < n {
+			continue
+		}
+
+		var step Step
+		found := combinations(digits, n, func(combo []int) bool {
+			var union, keep uint
+			for _, digit := range combo {
+				union |= places[digit]
+				keep |= digitMask(digit)
+			}
+			if bits.OnesCount(union) != n {
+				return false
+			}
+
+			var cells []int
+			var elims []Candidate
+			for i, pos := range ls.units[u] {
+				if union&(1<<i) == 0 {
+					continue
+				}
+				cells = append(cells, pos)
+				for _, digit := range maskDigits(ls.cands[pos] &^ keep) {
+					elims = append(elims, Candidate{Pos: pos, Digit: digit})
+				}
+			}
+			if len(elims) == 0 {
+				return false
+			}
+
+			step = Step{
+				Technique:    technique,
+				Digits:       append([]int(nil), combo...),
+				Cells:        cells,
+				Units:        []Unit{unitInfo(u)},
+				Eliminations: elims,
+			}
+			return true
+		})
+		if found {
+			return step, true
+		}
+	}
+	return Step{}, false
+}
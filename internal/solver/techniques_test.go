@@ -0,0 +1,467 @@
+package solver
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/rybkr/sudoku/internal/board"
+)
+
+// jigsawLayout is the standard layout with r3c3 and r1c4 swapped between
+// the first two regions, so region 1 covers r1c1-r1c4 and region 2 holds
+// r3c3, which shares no row, column or box with r1c5.
+const jigsawLayout = "000011222000111222001111222333444555333444555333444555666777888666777888666777888"
+
+// rc returns the position of row r, column c, both counted from 1.
+func rc(r, c int) int {
+	return board.MakePos(r-1, c-1)
+}
+
+// row and col return the cells of a row or column counted from 1.
+func row(r int) []int {
+	cells := make([]int, 9)
+	for c := range 9 {
+		cells[c] = rc(r, c+1)
+	}
+	return cells
+}
+
+func col(c int) []int {
+	cells := make([]int, 9)
+	for r := range 9 {
+		cells[r] = rc(r+1, c)
+	}
+	return cells
+}
+
+// box returns the cells of the standard 3x3 box counted from 1, left to
+// right and top to bottom.
+func box(n int) []int {
+	var cells []int
+	for r := range 3 {
+		for c := range 3 {
+			cells = append(cells, rc((n-1)/3*3+r+1, (n-1)%3*3+c+1))
+		}
+	}
+	return cells
+}
+
+// emptyGrid returns a solver for an empty board, where every cell still
+// has every candidate. Tests then narrow the candidates to the pattern they
+// need, without the easier techniques getting in the way.
+func emptyGrid(t *testing.T, layout string) *LogicalSolver {
+	t.Helper()
+	var l *board.Layout
+	if layout != "" {
+		var err error
+		if l, err = board.ParseLayout(layout); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewLogical(board.New(l))
+}
+
+// keep leaves digits as the only candidates of pos.
+func keep(ls *LogicalSolver, pos int, digits ...int) {
+	ls.cands[pos] = 0
+	for _, d := range digits {
+		ls.cands[pos] |= digitMask(d)
+	}
+}
+
+// confine removes digit from every cell of unit except those in places.
+func confine(ls *LogicalSolver, digit int, unit []int, places ...int) {
+	for _, pos := range unit {
+		if !slices.Contains(places, pos) {
+			ls.cands[pos] &^= digitMask(digit)
+		}
+	}
+}
+
+// each lists every digit at every cell, cell by cell.
+func each(cells []int, digits ...int) []Candidate {
+	var list []Candidate
+	for _, pos := range cells {
+		for _, d := range digits {
+			list = append(list, Candidate{Pos: pos, Digit: d})
+		}
+	}
+	return list
+}
+
+// except returns the cells of unit that are not in skip.
+func except(unit []int, skip ...int) []int {
+	var cells []int
+	for _, pos := range unit {
+		if !slices.Contains(skip, pos) {
+			cells = append(cells, pos)
+		}
+	}
+	return cells
+}
+
+// cellsIn returns the cells of the given rows in the given columns, row by
+// row.
+func cellsIn(rows, cols []int) []int {
+	var cells []int
+	for _, r := range rows {
+		for _, c := range cols {
+			cells = append(cells, rc(r, c))
+		}
+	}
+	return cells
+}
+
+// colsThenRows returns the cells of the given columns in the given rows,
+// column by column, the order the fish finders eliminate in.
+func colsThenRows(cols, rows []int) []int {
+	var cells []int
+	for _, c := range cols {
+		for _, r := range rows {
+			cells = append(cells, rc(r, c))
+		}
+	}
+	return cells
+}
+
+func TestFinders(t *testing.T) {
+	tests := []struct {
+		name   string
+		layout string
+		setup  func(ls *LogicalSolver)
+		find   func(ls *LogicalSolver) (Step, bool)
+		want   Step
+	}{
+		{
+			name: "hidden single",
+			setup: func(ls *LogicalSolver) {
+				confine(ls, 5, box(1), rc(2, 2))
+			},
+			find: (*LogicalSolver).findHiddenSingle,
+			want: Step{
+				Technique:  HiddenSingle,
+				Cells:      []int{rc(2, 2)},
+				Placements: []Candidate{{rc(2, 2), 5}},
+			},
+		},
+		{
+			name: "naked single",
+			setup: func(ls *LogicalSolver) {
+				keep(ls, rc(4, 7), 8)
+			},
+			find: (*LogicalSolver).findNakedSingle,
+			want: Step{
+				Technique:  NakedSingle,
+				Cells:      []int{rc(4, 7)},
+				Placements: []Candidate{{rc(4, 7), 8}},
+			},
+		},
+		{
+			name: "pointing",
+			setup: func(ls *LogicalSolver) {
+				confine(ls, 3, box(1), rc(1, 1), rc(1, 2))
+			},
+			find: (*LogicalSolver).findPointing,
+			want: Step{
+				Technique:    PointingCandidates,
+				Cells:        []int{rc(1, 1), rc(1, 2)},
+				Eliminations: each(except(row(1), box(1)...), 3),
+			},
+		},
+		{
+			name:   "pointing in a jigsaw region",
+			layout: jigsawLayout,
+			setup: func(ls *LogicalSolver) {
+				confine(ls, 4, ls.units[18][:], rc(1, 1), rc(1, 2), rc(1, 3), rc(1, 4))
+			},
+			find: (*LogicalSolver).findPointing,
+			want: Step{
+				Technique:    PointingCandidates,
+				Cells:        []int{rc(1, 1), rc(1, 2), rc(1, 3), rc(1, 4)},
+				Eliminations: each(cellsIn([]int{1}, []int{5, 6, 7, 8, 9}), 4),
+			},
+		},
+		{
+			name: "claiming",
+			setup: func(ls *LogicalSolver) {
+				confine(ls, 7, row(5), rc(5, 4), rc(5, 5))
+			},
+			find: (*LogicalSolver).findClaiming,
+			want: Step{
+				Technique:    ClaimingCandidates,
+				Cells:        []int{rc(5, 4), rc(5, 5)},
+				Eliminations: each(cellsIn([]int{4, 6}, []int{4, 5, 6}), 7),
+			},
+		},
+		{
+			name: "naked pair",
+			setup: func(ls *LogicalSolver) {
+				keep(ls, rc(1, 1), 1, 2)
+				keep(ls, rc(1, 5), 1, 2)
+			},
+			find: func(ls *LogicalSolver) (Step, bool) { return ls.findNakedSubset(2, NakedPair) },
+			want: Step{
+				Technique:    NakedPair,
+				Cells:        []int{rc(1, 1), rc(1, 5)},
+				Eliminations: each(except(row(1), rc(1, 1), rc(1, 5)), 1, 2),
+			},
+		},
+		{
+			name:   "naked pair in a jigsaw region",
+			layout: jigsawLayout,
+			setup: func(ls *LogicalSolver) {
+				keep(ls, rc(1, 5), 6, 7)
+				keep(ls, rc(3, 3), 6, 7)
+			},
+			find: func(ls *LogicalSolver) (Step, bool) { return ls.findNakedSubset(2, NakedPair) },
+			want: Step{
+				Technique: NakedPair,
+				Cells:     []int{rc(1, 5), rc(3, 3)},
+				Eliminations: each([]int{
+					rc(1, 6), rc(2, 4), rc(2, 5), rc(2, 6), rc(3, 4), rc(3, 5), rc(3, 6),
+				}, 6, 7),
+			},
+		},
+		{
+			name: "naked triple",
+			setup: func(ls *LogicalSolver) {
+				keep(ls, rc(1, 2), 1, 2)
+				keep(ls, rc(4, 2), 2, 3)
+				keep(ls, rc(7, 2), 1, 3)
+			},
+			find: func(ls *LogicalSolver) (Step, bool) { return ls.findNakedSubset(3, NakedTriple) },
+			want: Step{
+				Technique:    NakedTriple,
+				Cells:        []int{rc(1, 2), rc(4, 2), rc(7, 2)},
+				Eliminations: each(except(col(2), rc(1, 2), rc(4, 2), rc(7, 2)), 1, 2, 3),
+			},
+		},
+		{
+			name: "naked quad",
+			setup: func(ls *LogicalSolver) {
+				keep(ls, rc(9, 1), 1, 2)
+				keep(ls, rc(9, 3), 2, 3)
+				keep(ls, rc(9, 5), 3, 4)
+				keep(ls, rc(9, 9), 1, 4)
+			},
+			find: func(ls *LogicalSolver) (Step, bool) { return ls.findNakedSubset(4, NakedQuad) },
+			want: Step{
+				Technique:    NakedQuad,
+				Cells:        []int{rc(9, 1), rc(9, 3), rc(9, 5), rc(9, 9)},
+				Eliminations: each(except(row(9), rc(9, 1), rc(9, 3), rc(9, 5), rc(9, 9)), 1, 2, 3, 4),
+			},
+		},
+		{
+			name: "hidden pair",
+			setup: func(ls *LogicalSolver) {
+				confine(ls, 1, row(1), rc(1, 1), rc(1, 2))
+				confine(ls, 2, row(1), rc(1, 1), rc(1, 2))
+			},
+			find: func(ls *LogicalSolver) (Step, bool) { return ls.findHiddenSubset(2, HiddenPair) },
+			want: Step{
+				Technique:    HiddenPair,
+				Cells:        []int{rc(1, 1), rc(1, 2)},
+				Eliminations: each([]int{rc(1, 1), rc(1, 2)}, 3, 4, 5, 6, 7, 8, 9),
+			},
+		},
+		{
+			name: "hidden triple",
+			setup: func(ls *LogicalSolver) {
+				for _, d := range []int{4, 5, 6} {
+					confine(ls, d, col(5), rc(2, 5), rc(5, 5), rc(8, 5))
+				}
+			},
+			find: func(ls *LogicalSolver) (Step, bool) { return ls.findHiddenSubset(3, HiddenTriple) },
+			want: Step{
+				Technique:    HiddenTriple,
+				Cells:        []int{rc(2, 5), rc(5, 5), rc(8, 5)},
+				Eliminations: each([]int{rc(2, 5), rc(5, 5), rc(8, 5)}, 1, 2, 3, 7, 8, 9),
+			},
+		},
+		{
+			name: "hidden quad",
+			setup: func(ls *LogicalSolver) {
+				for _, d := range []int{1, 2, 3, 4} {
+					confine(ls, d, box(9), rc(7, 7), rc(7, 8), rc(8, 7), rc(9, 9))
+				}
+			},
+			find: func(ls *LogicalSolver) (Step, bool) { return ls.findHiddenSubset(4, HiddenQuad) },
+			want: Step{
+				Technique:    HiddenQuad,
+				Cells:        []int{rc(7, 7), rc(7, 8), rc(8, 7), rc(9, 9)},
+				Eliminations: each([]int{rc(7, 7), rc(7, 8), rc(8, 7), rc(9, 9)}, 5, 6, 7, 8, 9),
+			},
+		},
+		{
+			name: "x-wing",
+			setup: func(ls *LogicalSolver) {
+				confine(ls, 6, row(2), rc(2, 3), rc(2, 8))
+				confine(ls, 6, row(7), rc(7, 3), rc(7, 8))
+			},
+			find: func(ls *LogicalSolver) (Step, bool) { return ls.findFish(2, XWing) },
+			want: Step{
+				Technique:    XWing,
+				Cells:        []int{rc(2, 3), rc(2, 8), rc(7, 3), rc(7, 8)},
+				Eliminations: each(colsThenRows([]int{3, 8}, []int{1, 3, 4, 5, 6, 8, 9}), 6),
+			},
+		},
+		{
+			name: "swordfish",
+			setup: func(ls *LogicalSolver) {
+				confine(ls, 2, row(1), rc(1, 1), rc(1, 4))
+				confine(ls, 2, row(5), rc(5, 4), rc(5, 7))
+				confine(ls, 2, row(9), rc(9, 1), rc(9, 7))
+			},
+			find: func(ls *LogicalSolver) (Step, bool) { return ls.findFish(3, Swordfish) },
+			want: Step{
+				Technique:    Swordfish,
+				Cells:        []int{rc(1, 1), rc(1, 4), rc(5, 4), rc(5, 7), rc(9, 1), rc(9, 7)},
+				Eliminations: each(colsThenRows([]int{1, 4, 7}, []int{2, 3, 4, 6, 7, 8}), 2),
+			},
+		},
+		{
+			name: "jellyfish",
+			setup: func(ls *LogicalSolver) {
+				confine(ls, 9, row(1), rc(1, 2), rc(1, 5))
+				confine(ls, 9, row(3), rc(3, 5), rc(3, 8))
+				confine(ls, 9, row(5), rc(5, 8), rc(5, 9))
+				confine(ls, 9, row(7), rc(7, 2), rc(7, 9))
+			},
+			find: func(ls *LogicalSolver) (Step, bool) { return ls.findFish(4, Jellyfish) },
+			want: Step{
+				Technique: Jellyfish,
+				Cells: []int{
+					rc(1, 2), rc(1, 5), rc(3, 5), rc(3, 8), rc(5, 8), rc(5, 9), rc(7, 2), rc(7, 9),
+				},
+				Eliminations: each(colsThenRows([]int{2, 5, 8, 9}, []int{2, 4, 6, 8, 9}), 9),
+			},
+		},
+		{
+			name: "skyscraper",
+			setup: func(ls *LogicalSolver) {
+				confine(ls, 1, row(1), rc(1, 1), rc(1, 5))
+				confine(ls, 1, row(5), rc(5, 1), rc(5, 6))
+			},
+			find: (*LogicalSolver).findSkyscraper,
+			want: Step{
+				Technique:    Skyscraper,
+				Cells:        []int{rc(1, 5), rc(1, 1), rc(5, 1), rc(5, 6)},
+				Eliminations: each([]int{rc(2, 6), rc(3, 6), rc(4, 5), rc(6, 5)}, 1),
+			},
+		},
+		{
+			name: "2-string kite",
+			setup: func(ls *LogicalSolver) {
+				confine(ls, 5, row(1), rc(1, 2), rc(1, 7))
+				confine(ls, 5, col(1), rc(2, 1), rc(8, 1))
+			},
+			find: (*LogicalSolver).findTwoStringKite,
+			want: Step{
+				Technique:    TwoStringKite,
+				Cells:        []int{rc(1, 7), rc(1, 2), rc(2, 1), rc(8, 1)},
+				Eliminations: each([]int{rc(8, 7)}, 5),
+			},
+		},
+		{
+			name: "xy-wing",
+			setup: func(ls *LogicalSolver) {
+				keep(ls, rc(5, 5), 1, 2)
+				keep(ls, rc(1, 5), 2, 3)
+				keep(ls, rc(5, 1), 1, 3)
+			},
+			find: (*LogicalSolver).findXYWing,
+			want: Step{
+				Technique:    XYWing,
+				Cells:        []int{rc(5, 5), rc(1, 5), rc(5, 1)},
+				Eliminations: each([]int{rc(1, 1)}, 3),
+			},
+		},
+		{
+			name: "xyz-wing",
+			setup: func(ls *LogicalSolver) {
+				keep(ls, rc(2, 2), 1, 2, 3)
+				keep(ls, rc(2, 7), 1, 3)
+				keep(ls, rc(3, 1), 2, 3)
+			},
+			find: (*LogicalSolver).findXYZWing,
+			want: Step{
+				Technique:    XYZWing,
+				Cells:        []int{rc(2, 2), rc(2, 7), rc(3, 1)},
+				Eliminations: each([]int{rc(2, 1), rc(2, 3)}, 3),
+			},
+		},
+		{
+			name: "w-wing",
+			setup: func(ls *LogicalSolver) {
+				keep(ls, rc(1, 1), 4, 7)
+				keep(ls, rc(9, 9), 4, 7)
+				confine(ls, 4, row(5), rc(5, 1), rc(5, 9))
+			},
+			find: (*LogicalSolver).findWWing,
+			want: Step{
+				Technique:    WWing,
+				Cells:        []int{rc(1, 1), rc(5, 1), rc(5, 9), rc(9, 9)},
+				Eliminations: each([]int{rc(1, 9), rc(9, 1)}, 7),
+			},
+		},
+		{
+			name: "simple coloring trap",
+			setup: func(ls *LogicalSolver) {
+				// r1c1 = r1c5 = r5c5 = r5c2: either end holds the 8.
+				confine(ls, 8, row(1), rc(1, 1), rc(1, 5))
+				confine(ls, 8, col(5), rc(1, 5), rc(5, 5))
+				confine(ls, 8, row(5), rc(5, 5), rc(5, 2))
+			},
+			find: (*LogicalSolver).findSimpleColoring,
+			want: Step{
+				Technique:    SimpleColoring,
+				Cells:        []int{rc(1, 1), rc(1, 5), rc(5, 2), rc(5, 5)},
+				Eliminations: each([]int{rc(2, 2), rc(3, 2), rc(4, 1), rc(6, 1)}, 8),
+			},
+		},
+		{
+			name: "simple coloring wrap",
+			setup: func(ls *LogicalSolver) {
+				// r1c1 and r2c3 get the same color and share a box.
+				confine(ls, 3, row(1), rc(1, 1), rc(1, 6))
+				confine(ls, 3, col(6), rc(1, 6), rc(5, 6))
+				confine(ls, 3, row(5), rc(5, 6), rc(5, 3))
+				confine(ls, 3, col(3), rc(5, 3), rc(2, 3))
+			},
+			find: (*LogicalSolver).findSimpleColoring,
+			want: Step{
+				Technique:    SimpleColoring,
+				Cells:        []int{rc(1, 1), rc(1, 6), rc(2, 3), rc(5, 3), rc(5, 6)},
+				Eliminations: each([]int{rc(1, 1), rc(2, 3), rc(5, 6)}, 3),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ls := emptyGrid(t, tt.layout)
+			tt.setup(ls)
+			got, ok := tt.find(ls)
+			if !ok {
+				t.Fatalf("found nothing, want %v", tt.want)
+			}
+			if got.Technique != tt.want.Technique || !reflect.DeepEqual(got.Cells, tt.want.Cells) ||
+				!reflect.DeepEqual(got.Placements, tt.want.Placements) ||
+				!reflect.DeepEqual(got.Eliminations, tt.want.Eliminations) {
+				t.Errorf("found %v\nwant  %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestFindersNeedTheirPattern checks that a finder reports nothing on a grid
+// where every cell still holds every digit.
+func TestFindersNeedTheirPattern(t *testing.T) {
+	ls := emptyGrid(t, "")
+	for i, find := range ls.finders() {
+		if step, ok := find(); ok {
+			t.Errorf("finder %d found %v on an empty grid", i, step)
+		}
+	}
+}
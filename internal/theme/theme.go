@@ -0,0 +1,187 @@
+// Package theme loads the themes that style HTML booklets.
+//
+// A theme is a directory holding theme.json and, optionally, style.css
+// with extra CSS and header.html and footer.html to put at the top and
+// bottom of every page; a lone name.json file is a theme too. The theme is
+// named after its directory or file. Built-in themes are embedded in the
+// binary, and themes in later directories replace earlier ones of the same
+// name.
+package theme
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Default is the name of the theme used when none is chosen.
+const Default = "default"
+
+// BuiltIn is the Source of the embedded themes.
+const BuiltIn = "built-in"
+
+// ErrNotFound is returned by Find for an unknown theme.
+var ErrNotFound = errors.New("theme not found")
+
+//go:embed builtin
+var builtinFS embed.FS
+
+// Theme styles an HTML booklet.
+type Theme struct {
+	Name        string `json:"-"`
+	Description string `json:"description"`
+	// TitlePrefix heads the booklet. Titles, if any, is a pool of page
+	// titles picked at random; otherwise every page uses TitlePrefix.
+	TitlePrefix string   `json:"titlePrefix"`
+	Titles      []string `json:"titles"`
+	// BodyFont and HeadingFont are CSS font-family lists.
+	BodyFont    string `json:"bodyFont"`
+	HeadingFont string `json:"headingFont"`
+	// Class is added to the class of the page body, for CSS to select on.
+	Class string `json:"class"`
+	// CSS is added to the booklet's style sheet, and Header and Footer are
+	// HTML put at the top and bottom of every page.
+	CSS    string `json:"css"`
+	Header string `json:"header"`
+	Footer string `json:"footer"`
+	// Source is where the theme was loaded from: BuiltIn or a path.
+	Source string `json:"-"`
+}
+
+// Defaults for the fields a theme file leaves out.
+const (
+	defaultTitlePrefix = "Sudoku Puzzle"
+	defaultFont        = "Arial, sans-serif"
+)
+
+// UserDir returns the directory of the user's own themes, inside the user
+// configuration directory, or "" if there is none.
+func UserDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "sudoku", "themes")
+}
+
+// List returns the built-in themes and those in dirs, sorted by name. A
+// directory that does not exist is skipped.
+func List(dirs ...string) ([]*Theme, error) {
+	themes := map[string]*Theme{}
+	if err := load(themes, builtinFS, "builtin", BuiltIn); err != nil {
+		return nil, err
+	}
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err := load(themes, os.DirFS(dir), ".", dir); err != nil {
+			return nil, err
+		}
+	}
+
+	list := make([]*Theme, 0, len(themes))
+	for _, t := range themes {
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+// Find returns the theme called name from those List finds; "" is Default.
+func Find(name string, dirs ...string) (*Theme, error) {
+	if name == "" {
+		name = Default
+	}
+	list, err := List(dirs...)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, t := range list {
+		if strings.EqualFold(t.Name, name) {
+			return t, nil
+		}
+		names = append(names, t.Name)
+	}
+	return nil, fmt.Errorf("%w: %q (available: %s)", ErrNotFound, name, strings.Join(names, ", "))
+}
+
+// load reads every theme in directory dir of fsys into themes.
+func load(themes map[string]*Theme, fsys fs.FS, dir, source string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return fmt.Errorf("failed to read themes: %w", err)
+	}
+	for _, e := range entries {
+		var t *Theme
+		switch {
+		case e.IsDir():
+			sub, err := fs.Sub(fsys, path.Join(dir, e.Name()))
+			if err != nil {
+				return err
+			}
+			if _, err := fs.Stat(sub, "theme.json"); err != nil {
+				continue
+			}
+			t, err = read(sub, "theme.json")
+			if err != nil {
+				return fmt.Errorf("%s: %w", filepath.Join(source, e.Name(), "theme.json"), err)
+			}
+			t.Name = e.Name()
+			t.Source = filepath.Join(source, e.Name())
+		case path.Ext(e.Name()) == ".json":
+			t, err = read(fsys, path.Join(dir, e.Name()))
+			if err != nil {
+				return fmt.Errorf("%s: %w", filepath.Join(source, e.Name()), err)
+			}
+			t.Name = strings.TrimSuffix(e.Name(), ".json")
+			t.Source = filepath.Join(source, e.Name())
+		default:
+			continue
+		}
+		if source == BuiltIn {
+			t.Source = BuiltIn
+		}
+		themes[strings.ToLower(t.Name)] = t
+	}
+	return nil
+}
+
+// read parses the theme file name in fsys and adds the optional files next
+// to it: style.css after any css in the file, and header.html and
+// footer.html in place of the header and footer it gives.
+func read(fsys fs.FS, name string) (*Theme, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	t := &Theme{TitlePrefix: defaultTitlePrefix, BodyFont: defaultFont, HeadingFont: defaultFont}
+	if err := json.Unmarshal(data, t); err != nil {
+		return nil, err
+	}
+	if path.Base(name) != "theme.json" {
+		return t, nil
+	}
+
+	if css, err := fs.ReadFile(fsys, "style.css"); err == nil {
+		t.CSS = strings.TrimSpace(t.CSS + "\n" + string(css))
+	}
+	if header, err := fs.ReadFile(fsys, "header.html"); err == nil {
+		t.Header = string(header)
+	}
+	if footer, err := fs.ReadFile(fsys, "footer.html"); err == nil {
+		t.Footer = string(footer)
+	}
+	return t, nil
+}